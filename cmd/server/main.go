@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path"
+	"strings"
 	"syscall"
 	"time"
 
@@ -21,16 +23,145 @@ import (
 	"github.com/brianvoe/gofakeit/v5"
 )
 
+// uploadOverhead leaves room for the multipart framing around attachments
+const uploadOverhead = 64 * 1024
+
+// lockSweepInterval is how often expired document locks are released
+const lockSweepInterval = 15 * time.Second
+
+// livePersistInterval is how often live editing sessions are stored
+const livePersistInterval = 5 * time.Second
+
+// blobSweepGrace is how long stored content is kept before anything refers
+// to it, long enough for the upload that stored it to attach it
+const blobSweepGrace = time.Hour
+
+// isAttachmentUpload matches requests uploading whole attachments, possibly
+// several in one multipart body
+func isAttachmentUpload(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/attachments")
+}
+
+// isUploadChunk matches the requests a client sends while resuming an
+// upload; they come in bursts and get their own rate limit
+func isUploadChunk(r *http.Request) bool {
+	if r.Method != http.MethodPatch && r.Method != http.MethodHead {
+		return false
+	}
+	parent, _ := path.Split(r.URL.Path)
+	return strings.HasPrefix(r.URL.Path, "/documents/") && strings.HasSuffix(parent, "/uploads/")
+}
+
+// isImport matches document imports, whose bodies hold many documents
+func isImport(r *http.Request) bool {
+	return r.Method == http.MethodPost && r.URL.Path == "/documents/import"
+}
+
+// isWebSocketRoute matches the websocket endpoints, /notifications and
+// /documents/{id}/live
+func isWebSocketRoute(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	parent, last := path.Split(r.URL.Path)
+	return r.URL.Path == "/notifications" ||
+		last == "live" && strings.HasPrefix(parent, "/documents/") && strings.Count(parent, "/") == 3
+}
+
 // buildHTTPHandler wires middlewares and routes
 func buildHTTPHandler(
 	threatMonitor *security.ThreatMonitor,
 	documentHandler *deliveryhttp.DocumentHandler,
 	notificationHandler *websocket.NotificationHandler,
+	liveHandler *websocket.LiveHandler,
 	securityHandler *deliveryhttp.SecurityHandler,
+	templateHandler *deliveryhttp.TemplateHandler,
 	rateLimiter *middleware.RateLimiter,
 	requestValidator *middleware.RequestValidator,
 	securityHeaders *middleware.SecurityHeaders,
+	compression *middleware.Compression,
 ) http.Handler {
+	// Route requests. Patterns carry the HTTP method and path parameters;
+	// known paths hit with an unsupported method get a 405 with an Allow header.
+	router := http.NewServeMux()
+	router.HandleFunc("GET /documents", documentHandler.GetDocuments)
+	router.HandleFunc("POST /documents", documentHandler.CreateDocument)
+	router.HandleFunc("POST /documents:batch", documentHandler.BatchDocuments)
+	router.HandleFunc("GET /documents/{id}", documentHandler.GetDocument)
+	router.HandleFunc("PUT /documents/{id}", documentHandler.UpdateDocument)
+	router.HandleFunc("PATCH /documents/{id}", documentHandler.PatchDocument)
+	router.HandleFunc("DELETE /documents/{id}", documentHandler.DeleteDocument)
+	router.HandleFunc("GET /documents/trash", documentHandler.GetTrash)
+	router.HandleFunc("GET /documents/search", documentHandler.SearchDocuments)
+	router.HandleFunc("GET /documents/export", documentHandler.ExportDocuments)
+	router.HandleFunc("POST /documents/import", documentHandler.ImportDocuments)
+	router.HandleFunc("POST /documents/{id}/restore", documentHandler.RestoreDocument)
+	router.HandleFunc("POST /documents/{id}/attachments", documentHandler.UploadAttachments)
+	router.HandleFunc("GET /documents/{id}/attachments/{name}", documentHandler.DownloadAttachment)
+	router.HandleFunc("GET /documents/{id}/contributors", documentHandler.GetContributors)
+	router.HandleFunc("POST /documents/{id}/contributors", documentHandler.AddContributor)
+	router.HandleFunc("DELETE /documents/{id}/contributors/{userId}", documentHandler.RemoveContributor)
+	router.HandleFunc("GET /documents/{id}/lock", documentHandler.GetLock)
+	router.HandleFunc("POST /documents/{id}/lock", documentHandler.AcquireLock)
+	router.HandleFunc("DELETE /documents/{id}/lock", documentHandler.ReleaseLock)
+	router.HandleFunc("GET /documents/{id}/acl", documentHandler.GetAccess)
+	router.HandleFunc("PUT /documents/{id}/acl", documentHandler.SetAccess)
+	router.HandleFunc("GET /documents/{id}/tags", documentHandler.GetDocumentTags)
+	router.HandleFunc("POST /documents/{id}/tags", documentHandler.AddTags)
+	router.HandleFunc("DELETE /documents/{id}/tags/{tag}", documentHandler.RemoveTag)
+	router.HandleFunc("OPTIONS /documents/{id}/uploads", documentHandler.UploadOptions)
+	router.HandleFunc("POST /documents/{id}/uploads", documentHandler.CreateUpload)
+	router.HandleFunc("HEAD /documents/{id}/uploads/{upload}", documentHandler.GetUploadOffset)
+	router.HandleFunc("PATCH /documents/{id}/uploads/{upload}", documentHandler.AppendUpload)
+	router.HandleFunc("DELETE /documents/{id}/uploads/{upload}", documentHandler.TerminateUpload)
+	router.HandleFunc("POST /documents/{id}/release", documentHandler.ReleaseDocument)
+	router.HandleFunc("GET /documents/{id}/revisions", documentHandler.GetRevisions)
+	router.HandleFunc("GET /documents/{id}/revisions/{rev}", documentHandler.GetRevision)
+	router.HandleFunc("POST /documents/{id}/revisions/{rev}/revert", documentHandler.RevertDocument)
+	router.HandleFunc("GET /documents/{id}/diff", documentHandler.DiffDocument)
+	router.HandleFunc("GET /tags", documentHandler.GetTags)
+	// Answer CORS preflight requests on the document routes; OPTIONS on
+	// uploads is the tus discovery request, which answers them as well
+	for _, path := range []string{
+		"/documents",
+		"/documents:batch",
+		"/documents/{id}",
+		"/documents/trash",
+		"/documents/search",
+		"/documents/export",
+		"/documents/import",
+		"/documents/{id}/restore",
+		"/documents/{id}/attachments",
+		"/documents/{id}/attachments/{name}",
+		"/documents/{id}/contributors",
+		"/documents/{id}/contributors/{userId}",
+		"/documents/{id}/lock",
+		"/documents/{id}/acl",
+		"/documents/{id}/tags",
+		"/documents/{id}/tags/{tag}",
+		"/documents/{id}/uploads/{upload}",
+		"/documents/{id}/release",
+		"/documents/{id}/revisions",
+		"/documents/{id}/revisions/{rev}",
+		"/documents/{id}/revisions/{rev}/revert",
+		"/documents/{id}/diff",
+		"/tags",
+	} {
+		router.HandleFunc("OPTIONS "+path, documentHandler.Options)
+	}
+	router.HandleFunc("GET /templates", templateHandler.GetTemplates)
+	router.HandleFunc("POST /templates", templateHandler.CreateTemplate)
+	router.HandleFunc("GET /templates/{id}", templateHandler.GetTemplate)
+	router.HandleFunc("PUT /templates/{id}", templateHandler.UpdateTemplate)
+	router.HandleFunc("DELETE /templates/{id}", templateHandler.DeleteTemplate)
+	router.HandleFunc("/notifications", notificationHandler.HandleNotifications)
+	router.HandleFunc("GET /documents/{id}/live", liveHandler.HandleLive)
+	router.HandleFunc("/security/stats", securityHandler.GetSecurityStats)
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
 	base := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Threat monitoring
 		if threatMonitor.AnalyzeRequest(r) {
@@ -38,35 +169,21 @@ func buildHTTPHandler(
 			return
 		}
 
-		// Route requests
-		switch r.URL.Path {
-		case "/documents":
-			switch r.Method {
-			case "GET":
-				documentHandler.GetDocuments(w, r)
-			case "POST":
-				documentHandler.CreateDocument(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		case "/notifications":
-			notificationHandler.HandleNotifications(w, r)
-		case "/security/stats":
-			securityHandler.GetSecurityStats(w, r)
-		case "/health":
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("OK"))
-		default:
-			http.NotFound(w, r)
-		}
+		router.ServeHTTP(w, r)
 	})
 
 	// Apply middlewares in order
-	return rateLimiter.Middleware(
+	handler := rateLimiter.Middleware(
 		requestValidator.Middleware(
 			securityHeaders.Middleware(base),
 		),
 	)
+
+	// Compress every response, rejections included; nil disables compression
+	if compression != nil {
+		handler = compression.Middleware(handler)
+	}
+	return handler
 }
 
 func main() {
@@ -111,24 +228,111 @@ func main() {
 	documentRepo := repository.NewDocumentRepositoryImpl(cache)
 	userRepo := repository.NewUserRepositoryImpl()
 	notificationRepo := repository.NewNotificationRepositoryImpl()
+	revisionRepo := repository.NewRevisionRepositoryImpl()
+	templateRepo := repository.NewTemplateRepositoryImpl()
+	lockRepo := repository.NewLockRepositoryImpl()
+	groupRepo, err := repository.NewGroupRepositoryImpl(cfg.GroupsFile)
+	if err != nil {
+		logger.Error("Error loading groups", err)
+		os.Exit(1)
+	}
+	blobStore, err := repository.NewBlobStoreImpl(cfg.BlobDir)
+	if err != nil {
+		logger.Error("Error initializing blob store", err)
+		os.Exit(1)
+	}
+	uploadRepo, err := repository.NewUploadSessionRepositoryImpl(cfg.UploadDir)
+	if err != nil {
+		logger.Error("Error initializing upload staging", err)
+		os.Exit(1)
+	}
 
 	// Initialize use cases
-	documentUsecase := usecase.NewDocumentUsecase(documentRepo, userRepo)
+	documentUsecase := usecase.NewDocumentUsecase(documentRepo, userRepo, revisionRepo, groupRepo, lockRepo, cfg.LockTTL)
 	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, documentRepo, userRepo)
+	attachmentUsecase := usecase.NewAttachmentUsecase(documentUsecase, blobStore, uploadRepo, cfg.MaxAttachmentSize, cfg.UploadExpiry)
+	templateUsecase := usecase.NewTemplateUsecase(templateRepo, documentUsecase)
+	liveUsecase := usecase.NewLiveUsecase(documentUsecase)
+	userUsecase := usecase.NewUserUsecase(userRepo)
+
+	// Purge documents that outlived the trash retention, abandoned uploads
+	// and the content nothing refers to any more
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := documentUsecase.PurgeTrash(usecase.Internal(context.Background()), cfg.TrashRetention); err != nil {
+				logger.Error("Error purging trash", err)
+			}
+			if _, err := attachmentUsecase.PurgeExpiredUploads(usecase.Internal(context.Background())); err != nil {
+				logger.Error("Error purging expired uploads", err)
+			}
+			if _, err := attachmentUsecase.SweepBlobs(usecase.Internal(context.Background()), blobSweepGrace); err != nil {
+				logger.Error("Error sweeping attachment content", err)
+			}
+		}
+	}()
 
 	// Initialize handlers
 	notificationHandler := websocket.NewNotificationHandler(notificationUsecase)
-	documentHandler := deliveryhttp.NewDocumentHandler(documentUsecase).WithNotifier(notificationHandler.Hub())
+	// Changes to a document are only told to those who may read it
+	notificationHandler.Hub().WithAudience(documentUsecase)
+	documentHandler := deliveryhttp.NewDocumentHandler(documentUsecase).
+		WithAttachments(attachmentUsecase).
+		WithTemplates(templateUsecase).
+		WithSecurityLogger(securityLogger).
+		WithNotifier(notificationHandler.Hub())
+	liveHandler := websocket.NewLiveHandler(liveUsecase, notificationHandler.Hub()).
+		WithAllowedOrigins(cfg.AllowedOrigins)
+
+	// Announce locks whose lease ran out without being released
+	go func() {
+		ticker := time.NewTicker(lockSweepInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := documentHandler.ExpireLocks(usecase.Internal(context.Background())); err != nil {
+				logger.Error("Error expiring document locks", err)
+			}
+		}
+	}()
+
+	// Store what was edited in live sessions
+	go func() {
+		ticker := time.NewTicker(livePersistInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := liveHandler.Persist(usecase.Internal(context.Background())); err != nil {
+				logger.Error("Error storing live sessions", err)
+			}
+		}
+	}()
 
 	// Configure security middlewares
 	rateLimiter := middleware.NewRateLimiter(100, time.Minute)      // 100 requests per minute
 	requestValidator := middleware.NewRequestValidator(1024 * 1024) // 1MB max
 	securityHeaders := middleware.NewSecurityHeaders(true)          // Enable CSP
+	// A multipart upload may carry several attachments, but at least one of
+	// the largest size; every attachment is checked on its own as it is stored
+	requestValidator.WithBodyLimit(isAttachmentUpload, max(cfg.MaxUploadSize, cfg.MaxAttachmentSize)+uploadOverhead)
+	requestValidator.WithBodyLimit(isUploadChunk, cfg.MaxAttachmentSize+uploadOverhead)
+	requestValidator.WithBodyLimit(isImport, cfg.MaxImportSize)
+	requestValidator.WithActors(userUsecase)
+	requestValidator.WithWebSocketRoutes(isWebSocketRoute)
+	rateLimiter.WithLimit("uploads", isUploadChunk, 1000)
+	var compression *middleware.Compression
+	if cfg.Compression {
+		compression, err = middleware.NewCompression(cfg.CompressionMinSize, cfg.CompressionLevel)
+		if err != nil {
+			logger.Error("Error configuring compression", err)
+			os.Exit(1)
+		}
+	}
 	securityHandler := deliveryhttp.NewSecurityHandler(threatMonitor, rateLimiter, logRotator, cache)
+	templateHandler := deliveryhttp.NewTemplateHandler(templateUsecase)
 
 	// Configure routes with middlewares
 	mux := http.NewServeMux()
-	handler := buildHTTPHandler(threatMonitor, documentHandler, notificationHandler, securityHandler, rateLimiter, requestValidator, securityHeaders)
+	handler := buildHTTPHandler(threatMonitor, documentHandler, notificationHandler, liveHandler, securityHandler, templateHandler, rateLimiter, requestValidator, securityHeaders, compression)
 	mux.Handle("/", handler)
 
 	// Configure server
@@ -0,0 +1,357 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"frontend-challenge/pkg/cbor"
+	"frontend-challenge/pkg/jsonvalue"
+	"frontend-challenge/pkg/msgpack"
+)
+
+// Content negotiation errors
+var (
+	errNotAcceptable        = errors.New("none of the accepted media types can be produced")
+	errUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// codec encodes response bodies and decodes request bodies in one format
+type codec interface {
+	// mediaTypes lists the media types of the format, the preferred first
+	mediaTypes() []string
+	// tagSuffix is added to entity tags so that each format has its own;
+	// JSON, the default, has none
+	tagSuffix() string
+	marshal(v interface{}) ([]byte, error)
+	newDecoder(r io.Reader) bodyDecoder
+}
+
+// bodyDecoder decodes a request body. It is satisfied by json.Decoder.
+type bodyDecoder interface {
+	Decode(v interface{}) error
+	DisallowUnknownFields()
+}
+
+// codecRegistry picks codecs by media type
+type codecRegistry struct {
+	codecs []codec
+}
+
+// newCodecRegistry creates a registry whose first codec is the default
+func newCodecRegistry(codecs ...codec) *codecRegistry {
+	return &codecRegistry{codecs: codecs}
+}
+
+// codecs are the formats of request and response bodies
+var codecs = newCodecRegistry(jsonCodec{}, xmlCodec{}, msgpackCodec{}, cborCodec{})
+
+// mediaTypes lists the media types of all codecs
+func (c *codecRegistry) mediaTypes() []string {
+	var types []string
+	for _, codec := range c.codecs {
+		types = append(types, codec.mediaTypes()...)
+	}
+	return types
+}
+
+// acceptRange is one media range of an Accept header
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// specificity ranks */* below type/* below full media types
+func (a acceptRange) specificity() int {
+	switch {
+	case a.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(a.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matches reports whether the range covers a media type
+func (a acceptRange) matches(mediaType string) bool {
+	if a.mediaType == "*/*" || a.mediaType == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(a.mediaType, "*")
+	return ok && strings.HasSuffix(prefix, "/") && strings.HasPrefix(mediaType, prefix)
+}
+
+// parseAccept parses an Accept header, skipping malformed ranges
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// forAccept picks the codec for an Accept header and the media type to
+// answer with. Each media type takes the quality of the most specific range
+// covering it; the best quality wins, then the range that is more specific
+// or comes first, then the order of the registry. Without an Accept header
+// the default codec is used.
+func (c *codecRegistry) forAccept(header string) (codec, string, error) {
+	if strings.TrimSpace(header) == "" {
+		return c.codecs[0], c.codecs[0].mediaTypes()[0], nil
+	}
+	ranges := parseAccept(header)
+
+	var best codec
+	var bestType string
+	var bestQuality float64
+	bestSpecificity, bestIndex := -1, 0
+	for _, codec := range c.codecs {
+		for _, mediaType := range codec.mediaTypes() {
+			index, specificity := -1, -1
+			for i, r := range ranges {
+				if r.matches(mediaType) && r.specificity() > specificity {
+					index, specificity = i, r.specificity()
+				}
+			}
+			if index < 0 || ranges[index].quality == 0 {
+				continue
+			}
+			quality := ranges[index].quality
+			if best == nil || quality > bestQuality ||
+				(quality == bestQuality && (specificity > bestSpecificity ||
+					(specificity == bestSpecificity && index < bestIndex))) {
+				best, bestType, bestQuality, bestSpecificity, bestIndex = codec, mediaType, quality, specificity, index
+			}
+		}
+	}
+	if best == nil {
+		return nil, "", errNotAcceptable
+	}
+	return best, bestType, nil
+}
+
+// forContentType picks the codec for a Content-Type header. Bodies without
+// a Content-Type are taken to be in the default format.
+func (c *codecRegistry) forContentType(header string) (codec, error) {
+	if header == "" {
+		return c.codecs[0], nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedMediaType, header)
+	}
+	for _, codec := range c.codecs {
+		for _, t := range codec.mediaTypes() {
+			if t == mediaType {
+				return codec, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", errUnsupportedMediaType, mediaType)
+}
+
+// errorResponse is the body of error responses
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeResponse writes v in the format negotiated from the Accept header
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	codec, mediaType, err := codecs.forAccept(r.Header.Get("Accept"))
+	if err != nil {
+		writeNotAcceptable(w)
+		return
+	}
+	body, err := codec.marshal(v)
+	if err != nil {
+		httpError(w, r, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+
+	addVary(w.Header(), "Accept")
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// httpError writes an error message in the format negotiated from the
+// Accept header, or as plain text when none can be produced
+func httpError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	codec, mediaType, err := codecs.forAccept(r.Header.Get("Accept"))
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+	body, err := codec.marshal(errorResponse{Error: message})
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+
+	// Like http.Error, drop a length meant for the successful response
+	w.Header().Del("Content-Length")
+	addVary(w.Header(), "Accept")
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// writeNotAcceptable answers a request whose Accept header rules out all
+// formats
+func writeNotAcceptable(w http.ResponseWriter) {
+	addVary(w.Header(), "Accept")
+	http.Error(w, "Not acceptable; available media types: "+strings.Join(codecs.mediaTypes(), ", "), http.StatusNotAcceptable)
+}
+
+// requestDecoder returns a decoder for the request body in the format of
+// its Content-Type. Requests whose response could not be encoded are
+// refused before the body is read.
+func requestDecoder(r *http.Request) (bodyDecoder, error) {
+	if _, _, err := codecs.forAccept(r.Header.Get("Accept")); err != nil {
+		return nil, err
+	}
+	codec, err := codecs.forContentType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return codec.newDecoder(r.Body), nil
+}
+
+// decodeRequest decodes the request body into v
+func decodeRequest(r *http.Request, v interface{}) error {
+	decoder, err := requestDecoder(r)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(v)
+}
+
+// writeDecodeError reports a request body that could not be decoded
+func writeDecodeError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	switch {
+	case errors.Is(err, errNotAcceptable):
+		writeNotAcceptable(w)
+	case errors.Is(err, errUnsupportedMediaType):
+		httpError(w, r, err.Error()+"; supported media types: "+strings.Join(codecs.mediaTypes(), ", "), http.StatusUnsupportedMediaType)
+	default:
+		httpError(w, r, message+": "+err.Error(), http.StatusBadRequest)
+	}
+}
+
+// addVary adds a field to the Vary header unless it is listed already
+func addVary(header http.Header, field string) {
+	for _, value := range header.Values("Vary") {
+		for _, listed := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), field) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", field)
+}
+
+// jsonCodec is the default format
+type jsonCodec struct{}
+
+func (jsonCodec) mediaTypes() []string { return []string{"application/json"} }
+
+func (jsonCodec) tagSuffix() string { return "" }
+
+func (jsonCodec) marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (jsonCodec) newDecoder(r io.Reader) bodyDecoder { return json.NewDecoder(r) }
+
+// msgpackCodec encodes bodies as MessagePack
+type msgpackCodec struct{}
+
+func (msgpackCodec) mediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+func (msgpackCodec) tagSuffix() string { return "msgpack" }
+
+func (msgpackCodec) marshal(v interface{}) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (msgpackCodec) newDecoder(r io.Reader) bodyDecoder {
+	return &valueDecoder{r: r, decode: msgpack.Decode}
+}
+
+// cborCodec encodes bodies as CBOR
+type cborCodec struct{}
+
+func (cborCodec) mediaTypes() []string { return []string{"application/cbor"} }
+
+func (cborCodec) tagSuffix() string { return "cbor" }
+
+func (cborCodec) marshal(v interface{}) ([]byte, error) { return cbor.Marshal(v) }
+
+func (cborCodec) newDecoder(r io.Reader) bodyDecoder {
+	return &valueDecoder{r: r, decode: cbor.Decode}
+}
+
+// valueDecoder decodes a body into a JSON value and that, as JSON, into
+// the target, so that JSON struct tags and checks apply to every format
+type valueDecoder struct {
+	r      io.Reader
+	decode func([]byte) (interface{}, error)
+	strict bool
+}
+
+// Decode decodes the body into v. An empty body is io.EOF, like in JSON.
+func (d *valueDecoder) Decode(v interface{}) error {
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return io.EOF
+	}
+	value, err := d.decode(data)
+	if err != nil {
+		return err
+	}
+	return decodeJSONValue(value, v, d.strict)
+}
+
+// DisallowUnknownFields rejects object members no struct field takes
+func (d *valueDecoder) DisallowUnknownFields() { d.strict = true }
+
+// decodeJSONValue decodes a JSON value into v
+func decodeJSONValue(value, v interface{}, strict bool) error {
+	raw, err := jsonvalue.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestForAccept(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"no header", "", "application/json"},
+		{"blank header", "  ", "application/json"},
+		{"exact type", "application/cbor", "application/cbor"},
+		{"alias of a format", "application/x-msgpack", "application/x-msgpack"},
+		{"case and parameters", "Application/XML; charset=utf-8", "application/xml"},
+		{"any type", "*/*", "application/json"},
+		{"type wildcard", "text/*", "text/xml"},
+		{"subtype wildcard picks in registry order", "application/*", "application/json"},
+		{"unknown type before a known one", "image/png, application/cbor", "application/cbor"},
+		{"highest quality wins", "application/json;q=0.5, application/cbor;q=0.8", "application/cbor"},
+		{"quality over order", "application/xml;q=0.1, application/msgpack", "application/msgpack"},
+		{"first listed wins ties", "application/cbor, application/xml", "application/cbor"},
+		{"specific range wins ties with wildcards", "*/*, application/xml", "application/xml"},
+		{"specific range overrides a wildcard", "application/*;q=0.9, application/json;q=0.1", "application/xml"},
+		{"q=0 rules a type out", "application/json;q=0, */*", "application/xml"},
+		{"q=0 on a wildcard keeps specific ranges", "*/*;q=0, application/cbor", "application/cbor"},
+		{"q=0 with decimals", "application/json;q=0.000, application/cbor;q=0.001", "application/cbor"},
+		{"malformed range is skipped", "application/json;q=2, application/cbor;q=0.5", "application/cbor"},
+		{"unparsable quality is skipped", "application/json;q=high, text/xml", "text/xml"},
+		{"malformed media type is skipped", "application/json;;=, application/cbor", "application/cbor"},
+		{"spaces and empty items", " , application/cbor ;q=0.9 , ", "application/cbor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mediaType, err := codecs.forAccept(tt.accept)
+			if err != nil {
+				t.Fatalf("forAccept(%q): %v", tt.accept, err)
+			}
+			if mediaType != tt.want {
+				t.Errorf("forAccept(%q) = %s, want %s", tt.accept, mediaType, tt.want)
+			}
+		})
+	}
+}
+
+func TestForAcceptNotAcceptable(t *testing.T) {
+	for _, accept := range []string{
+		"image/png",
+		"text/html, text/plain",
+		"application/json;q=0",
+		"*/*;q=0",
+		"application/*;q=0, text/*;q=0",
+		"application/json;q=-1",
+		"garbage;;",
+	} {
+		if codec, mediaType, err := codecs.forAccept(accept); !errors.Is(err, errNotAcceptable) {
+			t.Errorf("forAccept(%q) = %v, %q, %v, want %v", accept, codec, mediaType, err, errNotAcceptable)
+		}
+	}
+}
+
+func TestForAcceptTagSuffix(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"application/json", ""},
+		{"text/xml", "xml"},
+		{"application/vnd.msgpack", "msgpack"},
+		{"application/cbor", "cbor"},
+	}
+	for _, tt := range tests {
+		codec, _, err := codecs.forAccept(tt.accept)
+		if err != nil {
+			t.Fatalf("forAccept(%q): %v", tt.accept, err)
+		}
+		if got := codec.tagSuffix(); got != tt.want {
+			t.Errorf("tag suffix for %s = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+		err         error
+	}{
+		{"", "application/json", nil},
+		{"application/json; charset=utf-8", "application/json", nil},
+		{"APPLICATION/CBOR", "application/cbor", nil},
+		{"application/x-msgpack", "application/msgpack", nil},
+		{"text/xml", "application/xml", nil},
+		{"text/plain", "", errUnsupportedMediaType},
+		{"application/*", "", errUnsupportedMediaType},
+		{"not a media type", "", errUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		codec, err := codecs.forContentType(tt.contentType)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("forContentType(%q): got %v, want %v", tt.contentType, err, tt.err)
+			continue
+		}
+		if err == nil && codec.mediaTypes()[0] != tt.want {
+			t.Errorf("forContentType(%q) = %s, want %s", tt.contentType, codec.mediaTypes()[0], tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fieldsTagPrefix starts the part of an entity tag that identifies the
+// fields a request selected
+const fieldsTagPrefix = "f"
+
+// cacheControl lets clients keep responses but makes them revalidate on
+// every use. Responses depend on the caller's access, so shared caches must
+// not keep them.
+const cacheControl = "private, no-cache"
+
+// notModified sets the validators of a response and reports whether the
+// request's conditions show the client already has it, in which case it
+// answers 304 Not Modified. If-Modified-Since is only looked at without
+// If-None-Match (RFC 9110 section 13.1.3).
+func notModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	// Without an acceptable representation there is nothing to validate
+	codec, _, err := codecs.forAccept(r.Header.Get("Accept"))
+	if err != nil {
+		return false
+	}
+	etag = withTagSuffix(etag, codec.tagSuffix())
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", cacheControl)
+	addVary(w.Header(), "Accept")
+	addVary(w.Header(), "Authorization")
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if !etagMatches(ifNoneMatch, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		// Last-Modified has a resolution of one second
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// representationETag returns the entity tag of the representation a
+// request negotiates: the tag of the JSON form, suffixed for other formats
+func representationETag(r *http.Request, etag string) string {
+	codec, _, err := codecs.forAccept(r.Header.Get("Accept"))
+	if err != nil {
+		return etag
+	}
+	return withTagSuffix(etag, codec.tagSuffix())
+}
+
+// withTagSuffix adds a suffix inside the quotes of an entity tag
+func withTagSuffix(etag, suffix string) string {
+	if suffix == "" {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + suffix + `"`
+}
+
+// projectedETag returns the entity tag of the fields of a document that a
+// request selects with ?fields=, which differs from the tag of the whole
+// document and of any other selection
+func projectedETag(r *http.Request, etag string) string {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return etag
+	}
+	sum := sha256.Sum256([]byte(sortedFields([]string{raw})))
+	return withTagSuffix(etag, fieldsTagPrefix+hex.EncodeToString(sum[:4]))
+}
+
+// baseETag removes the format and field selection suffixes from an entity
+// tag a client sent, so that If-Match accepts the tag of any representation
+// of a document
+func baseETag(tag string) string {
+	for _, codec := range codecs.codecs {
+		if suffix := codec.tagSuffix(); suffix != "" {
+			if base, ok := strings.CutSuffix(tag, "-"+suffix+`"`); ok {
+				tag = base + `"`
+				break
+			}
+		}
+	}
+	body, ok := strings.CutSuffix(tag, `"`)
+	i := strings.LastIndex(body, "-"+fieldsTagPrefix)
+	if !ok || i < 0 {
+		return tag
+	}
+	if selection, err := hex.DecodeString(body[i+1+len(fieldsTagPrefix):]); err != nil || len(selection) != 4 {
+		return tag
+	}
+	return body[:i] + `"`
+}
+
+// etagMatches reports whether a list of entity tags matches etag using the
+// weak comparison of If-None-Match
+func etagMatches(list, etag string) bool {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// collectionETag is the weak entity tag of a document collection, which
+// changes whenever any document does. The query selects, orders and shapes
+// the collection, so listings with different queries get different tags.
+func collectionETag(changes uint64, r *http.Request) string {
+	sum := sha256.Sum256([]byte(normalizedQuery(r)))
+	return fmt.Sprintf(`W/"%x-%s"`, changes, hex.EncodeToString(sum[:8]))
+}
+
+// normalizedQuery returns the query of a request with its parameters in
+// order and the selected fields sorted, so that equivalent queries match
+func normalizedQuery(r *http.Request) string {
+	query := r.URL.Query()
+	if raw, ok := query["fields"]; ok {
+		query["fields"] = []string{sortedFields(raw)}
+	}
+	return query.Encode()
+}
+
+// sortedFields joins lists of selected fields in order, so that the same
+// selection written differently compares equal
+func sortedFields(lists []string) string {
+	var fields []string
+	for _, list := range lists {
+		for _, field := range strings.Split(list, ",") {
+			fields = append(fields, strings.TrimSpace(field))
+		}
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
+}
+
+// collectionNotModified answers a request for a document collection with
+// 304 Not Modified if no document changed since the client got it. It
+// reports whether the response was written, with a 304 or an error.
+func (h *DocumentHandler) collectionNotModified(w http.ResponseWriter, r *http.Request) bool {
+	changes, changedAt, err := h.documentUsecase.LastChange(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return true
+	}
+	return notModified(w, r, collectionETag(changes, r), changedAt)
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+
+	"frontend-challenge/internal/usecase"
+)
+
+// GetAccess handles GET /documents/{id}/acl
+func (h *DocumentHandler) GetAccess(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	access, err := h.documentUsecase.GetAccess(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, access)
+}
+
+// SetAccess handles PUT /documents/{id}/acl
+func (h *DocumentHandler) SetAccess(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	var req usecase.AccessControl
+	if err := decodeRequest(r, &req); err != nil {
+		writeDecodeError(w, r, "Error decoding access control", err)
+		return
+	}
+
+	document, access, err := h.documentUsecase.SetAccess(r.Context(), r.PathValue("id"), req, parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, document, "document.updated")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	writeResponse(w, r, http.StatusOK, access)
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/usecase"
+)
+
+// UploadAttachments handles POST /documents/{id}/attachments. The body is
+// either multipart/form-data with one or more file parts, or the raw
+// content of a single file named by ?name= or a Content-Disposition
+// filename.
+func (h *DocumentHandler) UploadAttachments(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	if h.attachmentUsecase == nil {
+		httpError(w, r, "Attachment uploads are not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	next, err := uploadReader(r)
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	document, _, err := h.attachmentUsecase.UploadAttachments(r.Context(), r.PathValue("id"), parsePrecondition(r), next)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpError(w, r, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, document, "document.updated")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	writeResponse(w, r, http.StatusCreated, document)
+}
+
+// DownloadAttachment handles GET /documents/{id}/attachments/{name}. Range
+// and If-Range requests let clients resume interrupted downloads; the
+// strong ETag is the content checksum.
+func (h *DocumentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	if h.attachmentUsecase == nil {
+		httpError(w, r, "Attachment downloads are not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	attachment, content, err := h.attachmentUsecase.OpenAttachment(r.Context(), r.PathValue("id"), r.PathValue("name"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	// The MIME type comes from the uploader, so only types that browsers
+	// display without running anything are served as they are
+	mimeType, disposition := "application/octet-stream", "attachment"
+	if mediaType, _, err := mime.ParseMediaType(attachment.MimeType); err == nil && inlineTypes[mediaType] {
+		mimeType, disposition = attachment.MimeType, "inline"
+	}
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("ETag", `"`+attachment.Checksum+`"`)
+	// An upload may replace the content behind the name, so caches
+	// revalidate. Ranges and the ETag refer to the stored bytes, which must
+	// not be compressed on the way.
+	w.Header().Set("Cache-Control", "private, no-cache, no-transform")
+
+	// ServeContent answers Range, If-Range, If-None-Match and HEAD requests.
+	// Large files take longer to send than the server write timeout allows,
+	// so each chunk gets its own deadline instead.
+	http.ServeContent(newChunkDeadlineWriter(w), r, attachment.Name, time.Time{}, content)
+}
+
+// downloadChunkTimeout is how long a client may take to accept each chunk
+// of a download
+const downloadChunkTimeout = 30 * time.Second
+
+// chunkDeadlineWriter moves the write deadline forward on every write, so
+// a slow client can take as long as a download needs while one that stops
+// reading is still cut off
+type chunkDeadlineWriter struct {
+	http.ResponseWriter
+	controller *http.ResponseController
+}
+
+// newChunkDeadlineWriter wraps a response writer
+func newChunkDeadlineWriter(w http.ResponseWriter) *chunkDeadlineWriter {
+	return &chunkDeadlineWriter{ResponseWriter: w, controller: http.NewResponseController(w)}
+}
+
+// Write sends a chunk before its deadline
+func (w *chunkDeadlineWriter) Write(p []byte) (int, error) {
+	// Writers without deadlines are not limited by one either
+	_ = w.controller.SetWriteDeadline(time.Now().Add(downloadChunkTimeout))
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap gives http.ResponseController access to the connection
+func (w *chunkDeadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// inlineTypes are the attachment MIME types served as they are and shown
+// inline. Anything else, HTML and SVG included, is downloaded as
+// application/octet-stream.
+var inlineTypes = map[string]bool{
+	"text/plain":      true,
+	"text/csv":        true,
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"audio/mpeg":      true,
+	"audio/ogg":       true,
+	"audio/wav":       true,
+	"video/mp4":       true,
+	"video/webm":      true,
+}
+
+// uploadReader returns a function yielding the files of an upload request
+func uploadReader(r *http.Request) (func() (*usecase.Upload, error), error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		parts, err := r.MultipartReader()
+		if err != nil {
+			return nil, err
+		}
+		return func() (*usecase.Upload, error) {
+			for {
+				part, err := parts.NextPart()
+				if errors.Is(err, io.EOF) {
+					return nil, io.EOF
+				}
+				if err != nil {
+					return nil, fmt.Errorf("%w: %w", entity.ErrInvalidAttachment, err)
+				}
+				// Plain form fields carry no file
+				if part.FileName() == "" {
+					continue
+				}
+				return &usecase.Upload{
+					Name:     part.FileName(),
+					MimeType: part.Header.Get("Content-Type"),
+					Content:  uploadBody{part},
+				}, nil
+			}
+		}, nil
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+			name = params["filename"]
+		}
+	}
+	if name == "" {
+		return nil, errors.New("the file name must be given as ?name= or in Content-Disposition")
+	}
+
+	done := false
+	return func() (*usecase.Upload, error) {
+		if done {
+			return nil, io.EOF
+		}
+		done = true
+		return &usecase.Upload{
+			Name:     name,
+			MimeType: strings.TrimSpace(mediaType),
+			Content:  uploadBody{r.Body},
+		}, nil
+	}, nil
+}
+
+// uploadBody marks failures to read an upload as client errors, so an
+// interrupted or oversized upload is not reported as a server error
+type uploadBody struct {
+	r io.Reader
+}
+
+func (b uploadBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("%w: %w", entity.ErrInvalidAttachment, err)
+	}
+	return n, err
+}
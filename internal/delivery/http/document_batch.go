@@ -0,0 +1,216 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/usecase"
+)
+
+// batchOperation is the JSON form of one batch operation
+type batchOperation struct {
+	Op              string           `json:"op"`
+	ID              string           `json:"id"`
+	Document        *entity.Document `json:"document"`
+	IfMatch         string           `json:"ifMatch"`
+	ExpectedVersion string           `json:"expectedVersion"`
+}
+
+// batchItemResult is the JSON form of the outcome of one batch operation
+type batchItemResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status"`
+	ETag   string `json:"etag,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// batchReport is the JSON response of a batch
+type batchReport struct {
+	Atomic bool `json:"atomic"`
+	// PartialRollback is set when an atomic batch failed and some of its
+	// changes could not be undone
+	PartialRollback bool              `json:"partialRollback,omitempty"`
+	Succeeded       int               `json:"succeeded"`
+	Failed          int               `json:"failed"`
+	Results         []batchItemResult `json:"results"`
+}
+
+// BatchDocuments handles POST /documents:batch. The body is either a JSON
+// array of operations or, with Content-Type application/x-ndjson, one
+// operation per line. With ?atomic=true the batch is all-or-nothing.
+func (h *DocumentHandler) BatchDocuments(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	atomic := false
+	if raw := r.URL.Query().Get("atomic"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			httpError(w, r, "atomic must be true or false", http.StatusBadRequest)
+			return
+		}
+		atomic = parsed
+	}
+
+	operations, err := decodeBatch(r)
+	if err != nil {
+		writeDecodeError(w, r, "Error decoding batch", err)
+		return
+	}
+
+	now := time.Now()
+	ops := make([]usecase.BatchOperation, len(operations))
+	for i, op := range operations {
+		ops[i] = usecase.BatchOperation{
+			Action:   usecase.BatchAction(op.Op),
+			ID:       op.ID,
+			Document: op.Document,
+			Precondition: usecase.UpdatePrecondition{
+				ExpectedVersion: op.ExpectedVersion,
+			},
+		}
+		if op.IfMatch != "" {
+			ops[i].Precondition.IfMatch = []string{baseETag(op.IfMatch)}
+		}
+		if op.Document != nil {
+			if ops[i].Action == usecase.BatchCreate {
+				if err := validateRequiredFields(op.Document); err != nil {
+					httpError(w, r, fmt.Sprintf("operation %d: %v", i, err), http.StatusBadRequest)
+					return
+				}
+			}
+			applyTimestamps(op.Document, now)
+		}
+	}
+
+	results, err := h.documentUsecase.ExecuteBatch(r.Context(), ops, atomic)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	report := batchReport{Atomic: atomic, Results: make([]batchItemResult, len(results))}
+	var changed []*entity.Document
+	for i, result := range results {
+		item := batchItemResult{Index: i, Op: operations[i].Op, ID: operations[i].ID, Status: batchSuccessStatus(ops[i].Action)}
+		if item.ID == "" && operations[i].Document != nil {
+			item.ID = operations[i].Document.ID
+		}
+		switch {
+		case result.RolledBack:
+			item.Status = http.StatusFailedDependency
+			item.Error = "rolled back"
+		case result.RollbackErr != nil:
+			// The change is still in place, so it is reported and announced
+			report.PartialRollback = true
+			item.Status = statusForError(result.RollbackErr)
+			item.Error = "could not be rolled back: " + result.RollbackErr.Error()
+			if result.Document != nil {
+				item.ETag = result.Document.ETag()
+				changed = append(changed, result.Document)
+			}
+		case result.Err != nil:
+			h.logDenied(r, result.Err)
+			item.Status = statusForError(result.Err)
+			item.Error = result.Err.Error()
+		default:
+			item.ID = result.Document.ID
+			item.ETag = result.Document.ETag()
+			changed = append(changed, result.Document)
+		}
+		if item.Error != "" {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.Results[i] = item
+	}
+
+	// One notification for the whole batch instead of one per document;
+	// changes of a failed atomic batch only count if they were kept
+	if len(changed) > 0 && !(atomic && report.Failed > 0 && !report.PartialRollback) {
+		h.notifyBatch(r, changed)
+	}
+
+	status := http.StatusOK
+	if report.Failed > 0 {
+		status = http.StatusMultiStatus
+		// A partly rolled back batch left changes behind, like a non-atomic one
+		if atomic && !report.PartialRollback {
+			status = http.StatusUnprocessableEntity
+		}
+	}
+	writeResponse(w, r, status, report)
+}
+
+// decodeBatch reads the operations from an array or NDJSON body
+func decodeBatch(r *http.Request) ([]batchOperation, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-ndjson" {
+		var operations []batchOperation
+		if err := decodeRequest(r, &operations); err != nil {
+			return nil, err
+		}
+		return operations, nil
+	}
+
+	var operations []batchOperation
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var op batchOperation
+		if err := json.Unmarshal(data, &op); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		operations = append(operations, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+// batchSuccessStatus is the per-item status of a successful operation
+func batchSuccessStatus(action usecase.BatchAction) int {
+	switch action {
+	case usecase.BatchCreate:
+		return http.StatusCreated
+	case usecase.BatchDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
+
+// notifyBatch broadcasts a single notification covering every changed document
+func (h *DocumentHandler) notifyBatch(r *http.Request, documents []*entity.Document) {
+	if h.notifier == nil {
+		return
+	}
+	ids := make([]string, len(documents))
+	for i, document := range documents {
+		ids[i] = document.ID
+	}
+	n := entity.NewNotification(
+		r.Header.Get("user-id"),
+		r.Header.Get("user-name"),
+		"",
+		fmt.Sprintf("%d documents changed", len(documents)),
+		"document.batch",
+	)
+	n.DocumentIDs = ids
+	h.notifier.BroadcastNotification(n)
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// contributorRequest is the body of POST /documents/{id}/contributors
+type contributorRequest struct {
+	UserID string `json:"userId"`
+	Role   string `json:"role"`
+}
+
+// GetContributors handles GET /documents/{id}/contributors
+func (h *DocumentHandler) GetContributors(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	contributors, err := h.documentUsecase.GetContributors(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, contributors)
+}
+
+// AddContributor handles POST /documents/{id}/contributors
+func (h *DocumentHandler) AddContributor(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	var req contributorRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeDecodeError(w, r, "Error decoding contributor", err)
+		return
+	}
+	if req.UserID == "" {
+		httpError(w, r, "missing required field userId", http.StatusBadRequest)
+		return
+	}
+
+	document, contributor, err := h.documentUsecase.AddContributor(r.Context(), r.PathValue("id"), req.UserID, req.Role, parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, document, "document.updated")
+	h.notifyUser(r, document, contributor.ID, "document.contributor_added")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	writeResponse(w, r, http.StatusCreated, contributor)
+}
+
+// RemoveContributor handles DELETE /documents/{id}/contributors/{userId}
+func (h *DocumentHandler) RemoveContributor(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	document, contributor, err := h.documentUsecase.RemoveContributor(r.Context(), r.PathValue("id"), r.PathValue("userId"), parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, document, "document.updated")
+	h.notifyUser(r, document, contributor.ID, "document.contributor_removed")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notifyUser sends a document event to a single user
+func (h *DocumentHandler) notifyUser(r *http.Request, document *entity.Document, recipientID, notificationType string) {
+	if h.notifier == nil {
+		return
+	}
+	n := entity.NewNotification(
+		r.Header.Get("user-id"),
+		r.Header.Get("user-name"),
+		document.ID,
+		document.Title,
+		notificationType,
+	)
+	n.RecipientID = recipientID
+	h.notifier.BroadcastNotification(n)
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
-	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"frontend-challenge/internal/domain/entity"
@@ -16,11 +18,19 @@ type NotificationBroadcaster interface {
 	BroadcastNotification(notification *entity.Notification)
 }
 
+// AccessDeniedLogger records refused requests (implemented by security.SecurityLogger)
+type AccessDeniedLogger interface {
+	LogAccessDenied(r *http.Request, userID, resource, permission string)
+}
+
 // DocumentHandler handles HTTP requests for documents
 type DocumentHandler struct {
-	documentUsecase *usecase.DocumentUsecase
-	sanitizer       *security.Sanitizer
-	notifier        NotificationBroadcaster
+	documentUsecase   *usecase.DocumentUsecase
+	attachmentUsecase *usecase.AttachmentUsecase
+	templateUsecase   *usecase.TemplateUsecase
+	sanitizer         *security.Sanitizer
+	notifier          NotificationBroadcaster
+	securityLogger    AccessDeniedLogger
 }
 
 // NewDocumentHandler creates a new DocumentHandler instance
@@ -37,55 +47,175 @@ func (h *DocumentHandler) WithNotifier(n NotificationBroadcaster) *DocumentHandl
 	return h
 }
 
+// WithAttachments enables the attachment content endpoints
+func (h *DocumentHandler) WithAttachments(attachmentUsecase *usecase.AttachmentUsecase) *DocumentHandler {
+	h.attachmentUsecase = attachmentUsecase
+	return h
+}
+
+// WithTemplates enables creating documents from templates
+func (h *DocumentHandler) WithTemplates(templateUsecase *usecase.TemplateUsecase) *DocumentHandler {
+	h.templateUsecase = templateUsecase
+	return h
+}
+
+// WithSecurityLogger enables logging of access denials
+func (h *DocumentHandler) WithSecurityLogger(l AccessDeniedLogger) *DocumentHandler {
+	h.securityLogger = l
+	return h
+}
+
+// Options handles OPTIONS on the document routes, answering CORS preflight
+// requests
+func (h *DocumentHandler) Options(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // GetDocuments handles GET /documents
 func (h *DocumentHandler) GetDocuments(w http.ResponseWriter, r *http.Request) {
 	// Add security headers
 	h.addSecurityHeaders(w)
 
-	// Handle CORS
-	if r.Method == "OPTIONS" {
+	if wantsNDJSON(r.Header.Get("Accept")) {
+		h.streamDocuments(w, r)
 		return
 	}
 
-	documents, err := h.documentUsecase.GetAllDocuments(r.Context())
+	options, err := parseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(documents); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	if h.collectionNotModified(w, r) {
+		return
+	}
+
+	page, err := h.documentUsecase.ListDocuments(r.Context(), options)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
+
+	// Links to neighbouring pages (RFC 8288)
+	var links []string
+	if page.NextCursor != "" {
+		links = append(links, pageLink(r, page.NextCursor, "next"))
+	}
+	if page.PrevCursor != "" {
+		links = append(links, pageLink(r, page.PrevCursor, "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	writeResponse(w, r, http.StatusOK, project(page.Documents, fields))
 }
 
-// CreateDocument handles POST /documents
-func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request) {
+// searchResult is the JSON form of a search hit
+type searchResult struct {
+	Document   interface{}         `json:"document"`
+	Score      float64             `json:"score"`
+	Highlights map[string][]string `json:"highlights"`
+}
+
+// SearchDocuments handles GET /documents/search?q=
+func (h *DocumentHandler) SearchDocuments(w http.ResponseWriter, r *http.Request) {
 	// Add security headers
 	h.addSecurityHeaders(w)
 
-	// Handle CORS
-	if r.Method == "OPTIONS" {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			httpError(w, r, "limit must be an integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.collectionNotModified(w, r) {
 		return
 	}
 
+	hits, err := h.documentUsecase.SearchDocuments(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	results := make([]searchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = searchResult{Document: project(hit.Document, fields), Score: hit.Score, Highlights: hit.Highlights}
+	}
+
+	writeResponse(w, r, http.StatusOK, results)
+}
+
+// GetDocument handles GET /documents/{id}
+func (h *DocumentHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	document, err := h.documentUsecase.GetDocumentByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if notModified(w, r, projectedETag(r, document.ETag()), document.UpdatedAt) {
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, project(document, fields))
+}
+
+// CreateDocument handles POST /documents
+func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
 	// Only allow POST
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		httpError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Query().Has("template") {
+		h.createFromTemplate(w, r)
 		return
 	}
 
 	// Decode the document from the body
 	var document entity.Document
-	if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
-		http.Error(w, "Error decoding document: "+err.Error(), http.StatusBadRequest)
+	if err := decodeRequest(r, &document); err != nil {
+		writeDecodeError(w, r, "Error decoding document", err)
 		return
 	}
 
 	// Validate required fields and domain rules
 	if err := validateRequiredFields(&document); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		httpError(w, r, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -94,32 +224,194 @@ func (h *DocumentHandler) CreateDocument(w http.ResponseWriter, r *http.Request)
 
 	// Create the document in the cache
 	if err := h.documentUsecase.CreateDocument(r.Context(), &document); err != nil {
-		http.Error(w, "Error creating document: "+err.Error(), http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
 
-	userID := r.Header.Get("user-id")
-	userName := r.Header.Get("user-name")
-
 	// Emit notification if notifier is present
-	if h.notifier != nil {
-		n := entity.NewNotification(
-			userID,
-			userName,
-			document.ID,
-			document.Title,
-			"document.created",
-		)
-		h.notifier.BroadcastNotification(n)
-	}
+	h.notify(r, &document, "document.created")
 
 	// Respond with the created document (exactly as sent + server timestamps)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(document); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	writeResponse(w, r, http.StatusCreated, document)
+}
+
+// createFromTemplate handles POST /documents?template={id}. The body is
+// optional and may only give the ID of the new document.
+func (h *DocumentHandler) createFromTemplate(w http.ResponseWriter, r *http.Request) {
+	if h.templateUsecase == nil {
+		httpError(w, r, "templates are not available", http.StatusNotImplemented)
+		return
+	}
+
+	var body struct {
+		ID string `json:"id"`
+	}
+	decoder, err := requestDecoder(r)
+	if err == nil {
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&body)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeDecodeError(w, r, "Error decoding document", err)
+		return
+	}
+
+	document, err := h.templateUsecase.CreateDocument(r.Context(), r.URL.Query().Get("template"), body.ID)
+	if err != nil {
+		h.writeError(w, r, err)
 		return
 	}
+
+	h.notify(r, document, "document.created")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	writeResponse(w, r, http.StatusCreated, document)
+}
+
+// UpdateDocument handles PUT /documents/{id}
+func (h *DocumentHandler) UpdateDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	// Decode the full replacement document from the body
+	var document entity.Document
+	if err := decodeRequest(r, &document); err != nil {
+		writeDecodeError(w, r, "Error decoding document", err)
+		return
+	}
+
+	id := r.PathValue("id")
+	if document.ID != "" && document.ID != id {
+		httpError(w, r, "document id does not match the request path", http.StatusBadRequest)
+		return
+	}
+	document.ID = id
+
+	if err := validateRequiredFields(&document); err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	applyTimestamps(&document, time.Now())
+
+	updated, err := h.documentUsecase.UpdateDocument(r.Context(), &document, parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.respondUpdated(w, r, updated)
+}
+
+// DeleteDocument handles DELETE /documents/{id} by moving the document to the trash
+func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	deleted, err := h.documentUsecase.DeleteDocument(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, deleted, "document.deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTrash handles GET /documents/trash
+func (h *DocumentHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.collectionNotModified(w, r) {
+		return
+	}
+
+	documents, err := h.documentUsecase.GetTrash(r.Context())
+	if err != nil {
+		httpError(w, r, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, project(documents, fields))
+}
+
+// RestoreDocument handles POST /documents/{id}/restore
+func (h *DocumentHandler) RestoreDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	restored, err := h.documentUsecase.RestoreDocument(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, restored, "document.restored")
+
+	w.Header().Set("ETag", representationETag(r, restored.ETag()))
+	writeResponse(w, r, http.StatusOK, restored)
+}
+
+// ReleaseDocument handles POST /documents/{id}/release?bump=major|minor|patch
+func (h *DocumentHandler) ReleaseDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	released, err := h.documentUsecase.ReleaseDocument(r.Context(), r.PathValue("id"), r.URL.Query().Get("bump"), parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, released, "document.released")
+
+	w.Header().Set("ETag", representationETag(r, released.ETag()))
+	writeResponse(w, r, http.StatusOK, released)
+}
+
+// respondUpdated notifies listeners and writes an updated document
+func (h *DocumentHandler) respondUpdated(w http.ResponseWriter, r *http.Request, document *entity.Document) {
+	h.notify(r, document, "document.updated")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	writeResponse(w, r, http.StatusOK, document)
+}
+
+// writeError writes a use case error, logging access denials
+func (h *DocumentHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	h.logDenied(r, err)
+	writeUsecaseError(w, r, err)
+}
+
+// logDenied reports an access denial to the security log
+func (h *DocumentHandler) logDenied(r *http.Request, err error) {
+	var denied *entity.AccessDeniedError
+	if h.securityLogger == nil || !errors.As(err, &denied) {
+		return
+	}
+	h.securityLogger.LogAccessDenied(r, denied.UserID, "document:"+denied.DocumentID, denied.Permission)
+}
+
+// notify broadcasts a document event on behalf of the requesting user
+func (h *DocumentHandler) notify(r *http.Request, document *entity.Document, notificationType string) {
+	if h.notifier == nil {
+		return
+	}
+	n := entity.NewNotification(
+		r.Header.Get("user-id"),
+		r.Header.Get("user-name"),
+		document.ID,
+		document.Title,
+		notificationType,
+	)
+	h.notifier.BroadcastNotification(n)
 }
 
 // addSecurityHeaders adds security headers
@@ -129,10 +421,23 @@ func (h *DocumentHandler) addSecurityHeaders(w http.ResponseWriter) {
 	w.Header().Set("X-XSS-Protection", "1; mode=block")
 	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
 	w.Header().Set("Access-Control-Allow-Origin", "*") // TODO: Restrict in production
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 }
 
+// clearWriteDeadline lifts the server write timeout for a response that may
+// take long to send
+func clearWriteDeadline(w http.ResponseWriter) {
+	// Writers without deadlines are not limited by one either
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+}
+
+// clearReadDeadline lifts the server read timeout for a request body that
+// may take long to receive
+func clearReadDeadline(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+}
+
 // --- helpers to reduce cognitive complexity ---
 
 func validateRequiredFields(d *entity.Document) error {
@@ -142,9 +447,32 @@ func validateRequiredFields(d *entity.Document) error {
 	return d.Validate()
 }
 
+// pageLink builds a Link header entry pointing at another page of the current listing
+func pageLink(r *http.Request, cursor, rel string) string {
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	return "<" + r.URL.Path + "?" + query.Encode() + `>; rel="` + rel + `"`
+}
+
+// parsePrecondition reads If-Match and the expectedVersion query parameter
+func parsePrecondition(r *http.Request) usecase.UpdatePrecondition {
+	var precondition usecase.UpdatePrecondition
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		for _, tag := range strings.Split(ifMatch, ",") {
+			precondition.IfMatch = append(precondition.IfMatch, baseETag(strings.TrimSpace(tag)))
+		}
+	}
+	precondition.ExpectedVersion = r.URL.Query().Get("expectedVersion")
+	return precondition
+}
+
 func applyTimestamps(d *entity.Document, now time.Time) {
 	d.CreatedAt = now
 	d.UpdatedAt = now
+	applyContributorTimestamps(d, now)
+}
+
+func applyContributorTimestamps(d *entity.Document, now time.Time) {
 	for i := range d.Contributors {
 		if d.Contributors[i].CreatedAt.IsZero() {
 			d.Contributors[i].CreatedAt = now
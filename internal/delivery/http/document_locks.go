@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// GetLock handles GET /documents/{id}/lock
+func (h *DocumentHandler) GetLock(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	lock, err := h.documentUsecase.GetLock(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, lock)
+}
+
+// AcquireLock handles POST /documents/{id}/lock. The holder renews its
+// lease by posting again.
+func (h *DocumentHandler) AcquireLock(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	document, lock, renewed, err := h.documentUsecase.AcquireLock(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if !renewed {
+		h.notifyLock(r, document, lock, "document.locked")
+		status = http.StatusCreated
+	}
+
+	writeResponse(w, r, status, lock)
+}
+
+// ReleaseLock handles DELETE /documents/{id}/lock. With ?force=true an
+// admin of the document removes a lock held by somebody else.
+func (h *DocumentHandler) ReleaseLock(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	force, err := parseBool(r.URL.Query().Get("force"))
+	if err != nil {
+		httpError(w, r, "force must be true or false", http.StatusBadRequest)
+		return
+	}
+
+	document, lock, err := h.documentUsecase.ReleaseLock(r.Context(), r.PathValue("id"), force)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notifyLock(r, document, lock, "document.unlocked")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExpireLocks releases the locks whose lease ran out and announces them as
+// unlocked on behalf of their former holders
+func (h *DocumentHandler) ExpireLocks(ctx context.Context) error {
+	locks, err := h.documentUsecase.ExpireLocks(ctx)
+	if err != nil {
+		return err
+	}
+	if h.notifier == nil {
+		return nil
+	}
+	for _, lock := range locks {
+		title := ""
+		if document, err := h.documentUsecase.GetDocumentByID(ctx, lock.DocumentID); err == nil {
+			title = document.Title
+		}
+		n := entity.NewNotification(lock.UserID, lock.UserName, lock.DocumentID, title, "document.unlocked")
+		n.Lock = lock
+		h.notifier.BroadcastNotification(n)
+	}
+	return nil
+}
+
+// notifyLock broadcasts a lock event carrying the lock it is about
+func (h *DocumentHandler) notifyLock(r *http.Request, document *entity.Document, lock *entity.Lock, notificationType string) {
+	if h.notifier == nil {
+		return
+	}
+	n := entity.NewNotification(
+		r.Header.Get("user-id"),
+		r.Header.Get("user-name"),
+		document.ID,
+		document.Title,
+		notificationType,
+	)
+	n.Lock = lock
+	h.notifier.BroadcastNotification(n)
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/pkg/jsonpatch"
+)
+
+// Media types accepted by PATCH /documents/{id}
+const (
+	mediaTypeJSON       = "application/json"
+	mediaTypeMergePatch = "application/merge-patch+json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+// errInvalidPatchedDocument reports a patch whose result is not a document
+var errInvalidPatchedDocument = errors.New("patched document does not match the document schema")
+
+// documentPatch holds the fields a plain JSON PATCH request may change
+type documentPatch struct {
+	Title        *string               `json:"title"`
+	Version      *string               `json:"version"`
+	Attachments  *[]entity.Attachment  `json:"attachments"`
+	Files        *[]entity.Attachment  `json:"files"`
+	Contributors *[]entity.Contributor `json:"contributors"`
+	Tags         *[]string             `json:"tags"`
+}
+
+// PatchDocument handles PATCH /documents/{id}. The body is a JSON Merge
+// Patch (application/merge-patch+json), a JSON Patch
+// (application/json-patch+json) or a plain JSON object whose fields
+// replace the stored ones (application/json).
+func (h *DocumentHandler) PatchDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpError(w, r, "Error reading patch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var apply func(*entity.Document) error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case mediaTypeMergePatch:
+		apply = patchAsJSON(func(document []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(document, body)
+		})
+	case mediaTypeJSONPatch:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			httpError(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+		apply = patchAsJSON(patch.Apply)
+	case mediaTypeJSON:
+		var patch documentPatch
+		if err := json.Unmarshal(body, &patch); err != nil {
+			httpError(w, r, "Error decoding patch: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		apply = patch.apply
+	default:
+		w.Header().Set("Accept-Patch", mediaTypeMergePatch+", "+mediaTypeJSONPatch+", "+mediaTypeJSON)
+		httpError(w, r, "Unsupported patch media type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	now := time.Now()
+	updated, err := h.documentUsecase.PatchDocument(r.Context(), r.PathValue("id"), parsePrecondition(r), func(d *entity.Document) error {
+		if err := apply(d); err != nil {
+			return err
+		}
+		applyContributorTimestamps(d, now)
+		return nil
+	})
+	if err != nil {
+		h.logDenied(r, err)
+		writePatchError(w, r, err)
+		return
+	}
+
+	h.respondUpdated(w, r, updated)
+}
+
+// apply copies the fields present in a plain JSON patch
+func (p documentPatch) apply(d *entity.Document) error {
+	if p.Title != nil {
+		d.Title = *p.Title
+	}
+	if p.Version != nil {
+		d.Version = *p.Version
+	}
+	switch {
+	case p.Attachments != nil && p.Files != nil:
+		d.Attachments = entity.MergeAttachments(*p.Attachments, *p.Files)
+	case p.Attachments != nil:
+		d.Attachments = *p.Attachments
+	case p.Files != nil:
+		d.Attachments = *p.Files
+	}
+	if p.Contributors != nil {
+		d.Contributors = *p.Contributors
+	}
+	if p.Tags != nil {
+		d.Tags = *p.Tags
+	}
+	return nil
+}
+
+// patchAsJSON adapts a patch over the JSON form of a document into a
+// document modification. Server-managed fields keep their stored values.
+func patchAsJSON(patch func(document []byte) ([]byte, error)) func(*entity.Document) error {
+	return func(d *entity.Document) error {
+		original, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		patched, err := patch(original)
+		if err != nil {
+			return err
+		}
+
+		// Decoded through the JSON form, which rejects unknown fields
+		var decoded entity.DocumentJSON
+		decoder := json.NewDecoder(bytes.NewReader(patched))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&decoded); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidPatchedDocument, err)
+		}
+		result := *decoded.Document()
+
+		result.ID = d.ID
+		result.CreatedAt = d.CreatedAt
+		result.UpdatedAt = d.UpdatedAt
+		result.DeletedAt = d.DeletedAt
+		*d = result
+		return nil
+	}
+}
+
+// writePatchError maps patch failures to RFC 5789 status codes
+func writePatchError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		httpError(w, r, err.Error(), http.StatusConflict)
+	case errors.Is(err, jsonpatch.ErrUnprocessable), errors.Is(err, errInvalidPatchedDocument):
+		httpError(w, r, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		writeUsecaseError(w, r, err)
+	}
+}
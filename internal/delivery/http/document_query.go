@@ -0,0 +1,124 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+	"frontend-challenge/internal/usecase"
+)
+
+// listParams are query parameters of document listings that are not filters
+var listParams = []string{"limit", "cursor", "sort", "fields"}
+
+// filterParams maps filter query parameters to the filter field they set.
+// A trailing "~" on the name means "contains" instead of "equals".
+var filterParams = map[string]func(f *repository.DocumentFilter, value string) error{
+	"title": func(f *repository.DocumentFilter, value string) error {
+		f.Title = value
+		return nil
+	},
+	"title~": func(f *repository.DocumentFilter, value string) error {
+		f.TitleContains = value
+		return nil
+	},
+	"version": func(f *repository.DocumentFilter, value string) error {
+		f.Version = value
+		return nil
+	},
+	"contributor": func(f *repository.DocumentFilter, value string) error {
+		f.ContributorID = value
+		return nil
+	},
+	"tag": func(f *repository.DocumentFilter, value string) error {
+		tags, err := entity.NormalizeTags(strings.Split(value, ","))
+		if err != nil {
+			return err
+		}
+		f.Tags = tags
+		return nil
+	},
+	"tagMode": func(f *repository.DocumentFilter, value string) error {
+		switch mode := repository.TagMode(value); mode {
+		case repository.TagMatchAll, repository.TagMatchAny:
+			f.TagMode = mode
+			return nil
+		}
+		return fmt.Errorf("must be all or any")
+	},
+	"createdAfter":  timeFilter(func(f *repository.DocumentFilter) *time.Time { return &f.CreatedAfter }),
+	"createdBefore": timeFilter(func(f *repository.DocumentFilter) *time.Time { return &f.CreatedBefore }),
+	"updatedAfter":  timeFilter(func(f *repository.DocumentFilter) *time.Time { return &f.UpdatedAfter }),
+	"updatedBefore": timeFilter(func(f *repository.DocumentFilter) *time.Time { return &f.UpdatedBefore }),
+}
+
+// timeFilter parses an RFC 3339 timestamp into the selected filter field
+func timeFilter(field func(f *repository.DocumentFilter) *time.Time) func(f *repository.DocumentFilter, value string) error {
+	return func(f *repository.DocumentFilter, value string) error {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("must be an RFC 3339 timestamp")
+		}
+		*field(f) = t
+		return nil
+	}
+}
+
+// parseListOptions turns the query string of a document listing into list options
+func parseListOptions(query url.Values) (usecase.ListOptions, error) {
+	var options usecase.ListOptions
+
+	for name, values := range query {
+		if slices.Contains(listParams, name) {
+			continue
+		}
+		apply, ok := filterParams[name]
+		if !ok {
+			return options, fmt.Errorf("unknown filter field %q", name)
+		}
+		if len(values) != 1 || values[0] == "" {
+			return options, fmt.Errorf("filter %q needs exactly one non-empty value", name)
+		}
+		if err := apply(&options.Filter, values[0]); err != nil {
+			return options, fmt.Errorf("invalid filter %q: %v", name, err)
+		}
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		order, err := parseSort(raw)
+		if err != nil {
+			return options, err
+		}
+		options.Sort = order
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return options, fmt.Errorf("limit must be an integer")
+		}
+		options.Limit = limit
+	}
+	options.Cursor = query.Get("cursor")
+
+	return options, nil
+}
+
+// parseSort parses "-updatedAt,title" into a sort specification
+func parseSort(raw string) ([]repository.SortOrder, error) {
+	var order []repository.SortOrder
+	for _, part := range strings.Split(raw, ",") {
+		o := repository.SortOrder{Field: repository.SortField(strings.TrimPrefix(part, "-"))}
+		o.Descending = strings.HasPrefix(part, "-")
+		if !slices.Contains(repository.SortFields, o.Field) {
+			return nil, fmt.Errorf("unknown sort field %q", o.Field)
+		}
+		order = append(order, o)
+	}
+	return order, nil
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// GetRevisions handles GET /documents/{id}/revisions
+func (h *DocumentHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	revisions, err := h.documentUsecase.GetRevisions(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, revisions)
+}
+
+// GetRevision handles GET /documents/{id}/revisions/{rev}
+func (h *DocumentHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	number, err := parseRevision(r.PathValue("rev"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	revision, err := h.documentUsecase.GetRevision(r.Context(), r.PathValue("id"), number)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, revision)
+}
+
+// DiffDocument handles GET /documents/{id}/diff?from=&to=
+func (h *DocumentHandler) DiffDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	query := r.URL.Query()
+	var from, to int
+	var err error
+	if raw := query.Get("from"); raw != "" {
+		if from, err = parseRevision(raw); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+	if raw := query.Get("to"); raw != "" {
+		if to, err = parseRevision(raw); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+
+	diff, err := h.documentUsecase.DiffRevisions(r.Context(), r.PathValue("id"), from, to)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, diff)
+}
+
+// RevertDocument handles POST /documents/{id}/revisions/{rev}/revert
+func (h *DocumentHandler) RevertDocument(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	number, err := parseRevision(r.PathValue("rev"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	reverted, err := h.documentUsecase.RevertDocument(r.Context(), r.PathValue("id"), number, parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, reverted, "document.reverted")
+
+	w.Header().Set("ETag", representationETag(r, reverted.ETag()))
+	writeResponse(w, r, http.StatusOK, reverted)
+}
+
+// parseRevision parses a revision number, which starts at 1
+func parseRevision(raw string) (int, error) {
+	number, err := strconv.Atoi(raw)
+	if err != nil || number < 1 {
+		return 0, entity.ErrInvalidRevision
+	}
+	return number, nil
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// Streamed listings are flushed every streamFlushEvery documents, and
+// whenever streamFlushInterval has passed since the last flush
+const (
+	streamFlushEvery    = 100
+	streamFlushInterval = 200 * time.Millisecond
+)
+
+// errStreamDone stops a stream that has written enough documents
+var errStreamDone = errors.New("stream done")
+
+// wantsNDJSON reports whether an Accept header prefers NDJSON to the
+// formats of the codecs
+func wantsNDJSON(accept string) bool {
+	ndjson, other := 0.0, 0.0
+	for _, r := range parseAccept(accept) {
+		if r.mediaType == mediaTypeNDJSON {
+			ndjson = r.quality
+		} else if r.specificity() == 2 && r.quality > other {
+			other = r.quality
+		}
+	}
+	return ndjson > 0 && ndjson >= other
+}
+
+// streamDocuments handles GET /documents with Accept: application/x-ndjson.
+// Every matching document is written on its own line, in ID order, as it
+// is read from the repository; the listing is not paginated, but limit
+// caps the number of documents. It stops when the client goes away.
+func (h *DocumentHandler) streamDocuments(w http.ResponseWriter, r *http.Request) {
+	options, err := parseListOptions(r.URL.Query())
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if options.Sort != nil || options.Cursor != "" {
+		httpError(w, r, "sort and cursor are not supported when streaming", http.StatusBadRequest)
+		return
+	}
+	if options.Limit < 0 {
+		httpError(w, r, entity.ErrInvalidPageLimit.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The status line waits for the first document, so that failures to
+	// start are still reported as errors
+	started := false
+	start := func() {
+		addVary(w.Header(), "Accept")
+		w.Header().Set("Content-Type", mediaTypeNDJSON)
+		w.WriteHeader(http.StatusOK)
+		started = true
+	}
+
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	written, pending := 0, 0
+	lastFlush := time.Now()
+	err = h.documentUsecase.StreamDocuments(r.Context(), options.Filter, func(d *entity.Document) error {
+		if !started {
+			start()
+		}
+		if err := encoder.Encode(project(d, fields)); err != nil {
+			return err
+		}
+		written++
+		pending++
+		if pending >= streamFlushEvery || time.Since(lastFlush) >= streamFlushInterval {
+			if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+			pending, lastFlush = 0, time.Now()
+		}
+		if options.Limit > 0 && written == options.Limit {
+			return errStreamDone
+		}
+		return nil
+	})
+	switch {
+	case err == nil || errors.Is(err, errStreamDone):
+		if !started {
+			start()
+		}
+	case !started:
+		h.writeError(w, r, err)
+	case !errors.Is(err, context.Canceled):
+		// The status line is gone already; the client sees a truncated body
+		log.Printf("Error streaming documents: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+)
+
+// tagsRequest is the body of POST /documents/{id}/tags
+type tagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// GetTags handles GET /tags
+func (h *DocumentHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	if h.collectionNotModified(w, r) {
+		return
+	}
+
+	counts, err := h.documentUsecase.GetTags(r.Context())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, counts)
+}
+
+// GetDocumentTags handles GET /documents/{id}/tags
+func (h *DocumentHandler) GetDocumentTags(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	tags, err := h.documentUsecase.GetDocumentTags(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, tags)
+}
+
+// AddTags handles POST /documents/{id}/tags
+func (h *DocumentHandler) AddTags(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	var req tagsRequest
+	if err := decodeRequest(r, &req); err != nil {
+		writeDecodeError(w, r, "Error decoding tags", err)
+		return
+	}
+	if len(req.Tags) == 0 {
+		httpError(w, r, "missing required field tags", http.StatusBadRequest)
+		return
+	}
+
+	document, err := h.documentUsecase.AddTags(r.Context(), r.PathValue("id"), req.Tags, parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, document, "document.updated")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	writeResponse(w, r, http.StatusOK, document.Tags)
+}
+
+// RemoveTag handles DELETE /documents/{id}/tags/{tag}
+func (h *DocumentHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	document, err := h.documentUsecase.RemoveTag(r.Context(), r.PathValue("id"), r.PathValue("tag"), parsePrecondition(r))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.notify(r, document, "document.updated")
+
+	w.Header().Set("ETag", representationETag(r, document.ETag()))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,449 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/usecase"
+)
+
+// Transfer formats
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	mediaTypeCSV    = "text/csv"
+	mediaTypeNDJSON = "application/x-ndjson"
+)
+
+// exportFlushEvery is how many documents are written between flushes
+const exportFlushEvery = 100
+
+// maxNDJSONLine bounds a single NDJSON record
+const maxNDJSONLine = 1 << 20
+
+// CSV columns. Nested lists take one cell per field, holding the value of
+// every item on its own line; the n-th line of attachments.size belongs to
+// the n-th line of attachments.name.
+var csvColumns = []string{
+	"id", "title", "version", "createdAt", "updatedAt",
+	"attachments.name", "attachments.size", "attachments.mimeType", "attachments.checksum",
+	"contributors.id", "contributors.name", "contributors.role", "tags",
+	"ownerId", "acl.userId", "acl.group", "acl.permission",
+}
+
+// csvRequiredColumns must be present in an imported CSV header
+var csvRequiredColumns = []string{"id", "title", "version"}
+
+// csvItemSeparator separates the items of a nested list within a cell
+const csvItemSeparator = "\n"
+
+// ExportDocuments handles GET /documents/export?format=csv|ndjson
+func (h *DocumentHandler) ExportDocuments(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatNDJSON
+	}
+
+	var write func(*entity.Document) error
+	var flush func() error
+	switch format {
+	case formatNDJSON:
+		w.Header().Set("Content-Type", mediaTypeNDJSON)
+		encoder := json.NewEncoder(w)
+		write = func(d *entity.Document) error { return encoder.Encode(d) }
+		flush = func() error { return nil }
+	case formatCSV:
+		w.Header().Set("Content-Type", mediaTypeCSV+"; charset=utf-8")
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvColumns); err != nil {
+			return
+		}
+		write = func(d *entity.Document) error { return writer.Write(csvRecord(d)) }
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		httpError(w, r, "format must be csv or ndjson", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "documents." + format}))
+
+	// Exporting every document may take longer than the server timeouts
+	clearWriteDeadline(w)
+
+	flusher, _ := w.(http.Flusher)
+	written := 0
+	err := h.documentUsecase.ExportDocuments(r.Context(), func(d *entity.Document) error {
+		if err := write(d); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// The status line is gone already; the client sees a truncated body
+		log.Printf("Error exporting documents: %v", err)
+	}
+}
+
+// ImportDocuments handles POST /documents/import. The body is CSV (text/csv)
+// or NDJSON (application/x-ndjson), or as given by ?format=. With
+// ?dryRun=true records are only checked. ?onConflict=error|skip|replace
+// decides what happens to documents that already exist.
+func (h *DocumentHandler) ImportDocuments(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case mediaTypeCSV:
+			format = formatCSV
+		case mediaTypeNDJSON:
+			format = formatNDJSON
+		}
+	}
+	dryRun, err := parseBool(query.Get("dryRun"))
+	if err != nil {
+		httpError(w, r, "dryRun must be true or false", http.StatusBadRequest)
+		return
+	}
+
+	// Large imports take longer to receive and process than the server
+	// timeouts allow
+	clearReadDeadline(w)
+	clearWriteDeadline(w)
+
+	var next func() (*usecase.ImportRecord, error)
+	switch format {
+	case formatNDJSON:
+		next = ndjsonRecords(r.Body)
+	case formatCSV:
+		next, err = csvRecords(r.Body)
+		if err != nil {
+			h.logDenied(r, err)
+			writeImportError(w, r, err)
+			return
+		}
+	default:
+		httpError(w, r, "the body must be text/csv or application/x-ndjson", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	opts := usecase.ImportOptions{DryRun: dryRun, OnConflict: query.Get("onConflict")}
+	report, err := h.documentUsecase.ImportDocuments(r.Context(), opts, next)
+	if err != nil {
+		h.logDenied(r, err)
+		writeImportError(w, r, err)
+		return
+	}
+
+	if !dryRun && report.Imported > 0 {
+		h.notifyImport(r, report)
+	}
+
+	status := http.StatusOK
+	switch {
+	case report.Failed > 0 && report.Failed == report.Processed:
+		status = http.StatusUnprocessableEntity
+	case report.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	writeResponse(w, r, status, report)
+}
+
+// notifyImport emits a single notification for an import instead of one
+// per document; clients are expected to reload their lists
+func (h *DocumentHandler) notifyImport(r *http.Request, report *usecase.ImportReport) {
+	if h.notifier == nil {
+		return
+	}
+	n := entity.NewNotification(
+		r.Header.Get("user-id"),
+		r.Header.Get("user-name"),
+		"",
+		fmt.Sprintf("%d documents imported", report.Imported),
+		"document.imported",
+	)
+	n.DocumentIDs = report.ImportedIDs
+	h.notifier.BroadcastNotification(n)
+}
+
+// writeImportError reports an input that could not be read at all
+func writeImportError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		httpError(w, r, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	writeUsecaseError(w, r, err)
+}
+
+// parseBool parses an optional boolean query parameter
+func parseBool(raw string) (bool, error) {
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// ndjsonRecords reads one document per line, skipping blank lines
+func ndjsonRecords(body io.Reader) func() (*usecase.ImportRecord, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+	line := 0
+	return func() (*usecase.ImportRecord, error) {
+		for scanner.Scan() {
+			line++
+			raw := bytes.TrimSpace(scanner.Bytes())
+			if len(raw) == 0 {
+				continue
+			}
+			record := &usecase.ImportRecord{Line: line}
+			decoder := json.NewDecoder(bytes.NewReader(raw))
+			decoder.DisallowUnknownFields()
+			var document entity.DocumentJSON
+			if err := decoder.Decode(&document); err != nil {
+				record.Err = fmt.Errorf("invalid JSON: %v", err)
+			} else {
+				record.Document = document.Document()
+			}
+			return record, nil
+		}
+		if err := scanner.Err(); err != nil {
+			if errors.Is(err, bufio.ErrTooLong) {
+				return nil, fmt.Errorf("%w: line %d is longer than %d bytes", entity.ErrInvalidImport, line+1, maxNDJSONLine)
+			}
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// csvRecords reads the header and returns a reader of the documents in the
+// following rows. Columns are matched by name, so their order is free and
+// optional ones may be left out.
+func csvRecords(body io.Reader) (func() (*usecase.ImportRecord, error), error) {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return func() (*usecase.ImportRecord, error) { return nil, io.EOF }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", entity.ErrInvalidImport, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	known := make(map[string]bool, len(csvColumns))
+	for _, name := range csvColumns {
+		known[name] = true
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !known[name] {
+			return nil, fmt.Errorf("%w: unknown column %q", entity.ErrInvalidImport, name)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("%w: column %q appears twice", entity.ErrInvalidImport, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range csvRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", entity.ErrInvalidImport, name)
+		}
+	}
+
+	return func() (*usecase.ImportRecord, error) {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount) {
+			// The row is readable, just not aligned with the header
+			return &usecase.ImportRecord{Line: parseErr.StartLine, Err: errors.New("wrong number of fields")}, nil
+		}
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%w: %v", entity.ErrInvalidImport, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		document, err := documentFromCSV(row, columns)
+		return &usecase.ImportRecord{Line: line, Document: document, Err: err}, nil
+	}, nil
+}
+
+// csvRecord maps a document to a row of csvColumns
+func csvRecord(d *entity.Document) []string {
+	var names, sizes, mimeTypes, checksums []string
+	for _, a := range d.Attachments {
+		names = append(names, a.Name)
+		sizes = append(sizes, strconv.FormatInt(a.Size, 10))
+		mimeTypes = append(mimeTypes, a.MimeType)
+		checksums = append(checksums, a.Checksum)
+	}
+	var ids, userNames, roles []string
+	for _, c := range d.Contributors {
+		ids = append(ids, c.ID)
+		userNames = append(userNames, c.Name)
+		roles = append(roles, c.Role)
+	}
+	var grantUsers, grantGroups, permissions []string
+	for _, g := range d.ACL {
+		grantUsers = append(grantUsers, g.UserID)
+		grantGroups = append(grantGroups, g.Group)
+		permissions = append(permissions, g.Permission)
+	}
+	return []string{
+		d.ID, d.Title, d.Version,
+		d.CreatedAt.Format(time.RFC3339Nano), d.UpdatedAt.Format(time.RFC3339Nano),
+		joinItems(names), joinItems(sizes), joinItems(mimeTypes), joinItems(checksums),
+		joinItems(ids), joinItems(userNames), joinItems(roles), joinItems(d.Tags),
+		d.OwnerID, joinItems(grantUsers), joinItems(grantGroups), joinItems(permissions),
+	}
+}
+
+// documentFromCSV maps a row back to a document
+func documentFromCSV(row []string, columns map[string]int) (*entity.Document, error) {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return row[i]
+		}
+		return ""
+	}
+
+	d := &entity.Document{
+		ID:      strings.TrimSpace(cell("id")),
+		Title:   cell("title"),
+		Version: strings.TrimSpace(cell("version")),
+	}
+	for name, target := range map[string]*time.Time{"createdAt": &d.CreatedAt, "updatedAt": &d.UpdatedAt} {
+		if raw := strings.TrimSpace(cell(name)); raw != "" {
+			t, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return d, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+			}
+			*target = t
+		}
+	}
+
+	names := splitItems(cell("attachments.name"))
+	sizes, mimeTypes, checksums := splitItems(cell("attachments.size")), splitItems(cell("attachments.mimeType")), splitItems(cell("attachments.checksum"))
+	if err := checkItemCounts("attachments", len(names), sizes, mimeTypes, checksums); err != nil {
+		return d, err
+	}
+	for i, name := range names {
+		attachment := entity.NewAttachment(name)
+		if sizes != nil && sizes[i] != "" && sizes[i] != "0" {
+			size, err := strconv.ParseInt(sizes[i], 10, 64)
+			if err != nil || size < 0 {
+				return d, fmt.Errorf("attachments.size %q is not a size", sizes[i])
+			}
+			attachment.Size = size
+		}
+		if mimeTypes != nil {
+			attachment.MimeType = mimeTypes[i]
+		}
+		if checksums != nil {
+			attachment.Checksum = checksums[i]
+		}
+		d.Attachments = append(d.Attachments, attachment)
+	}
+
+	ids := splitItems(cell("contributors.id"))
+	userNames, roles := splitItems(cell("contributors.name")), splitItems(cell("contributors.role"))
+	if err := checkItemCounts("contributors", len(ids), userNames, roles); err != nil {
+		return d, err
+	}
+	for i, id := range ids {
+		var user entity.User
+		user.ID = id
+		if userNames != nil {
+			user.Name = userNames[i]
+		}
+		contributor := entity.NewContributor(user, "")
+		if roles != nil {
+			contributor.Role = roles[i]
+		}
+		d.Contributors = append(d.Contributors, contributor)
+	}
+	d.Tags = splitItems(cell("tags"))
+
+	d.OwnerID = strings.TrimSpace(cell("ownerId"))
+	permissions := splitItems(cell("acl.permission"))
+	grantUsers, grantGroups := splitItems(cell("acl.userId")), splitItems(cell("acl.group"))
+	if err := checkItemCounts("acl", len(permissions), grantUsers, grantGroups); err != nil {
+		return d, err
+	}
+	for i, permission := range permissions {
+		grant := entity.Grant{Permission: permission}
+		if grantUsers != nil {
+			grant.UserID = grantUsers[i]
+		}
+		if grantGroups != nil {
+			grant.Group = grantGroups[i]
+		}
+		d.ACL = append(d.ACL, grant)
+	}
+	return d, nil
+}
+
+// joinItems puts the items of a nested list into one cell
+func joinItems(items []string) string {
+	return strings.Join(items, csvItemSeparator)
+}
+
+// splitItems reads the items of a nested list from a cell; an empty cell
+// has none
+func splitItems(cell string) []string {
+	cell = strings.ReplaceAll(cell, "\r\n", "\n")
+	if strings.TrimSpace(cell) == "" {
+		return nil
+	}
+	return strings.Split(cell, csvItemSeparator)
+}
+
+// checkItemCounts makes sure the optional cells of a nested list are empty
+// or hold one line per item
+func checkItemCounts(list string, count int, cells ...[]string) error {
+	for _, items := range cells {
+		if items != nil && len(items) != count {
+			return fmt.Errorf("%s columns hold different numbers of lines", list)
+		}
+	}
+	return nil
+}
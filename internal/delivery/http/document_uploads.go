@@ -0,0 +1,179 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// tus 1.0 protocol constants (https://tus.io/protocols/resumable-upload)
+const (
+	tusVersion            = "1.0.0"
+	tusExtensions         = "creation,termination,expiration"
+	mediaTypeOffsetOctets = "application/offset+octet-stream"
+)
+
+// UploadOptions handles OPTIONS /documents/{id}/uploads, advertising the
+// supported tus version and extensions
+func (h *DocumentHandler) UploadOptions(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	if h.attachmentUsecase != nil {
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.attachmentUsecase.MaxSize(), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateUpload handles POST /documents/{id}/uploads (tus creation). The
+// file name and type come from the filename and filetype metadata.
+func (h *DocumentHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	if !h.tusRequest(w, r) {
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		httpError(w, r, "Upload-Length must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	metadata, err := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+	session, document, err := h.attachmentUsecase.CreateUpload(r.Context(), id, metadata["filename"], metadata["filetype"], length)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if document != nil {
+		h.notify(r, document, "document.attachment_added")
+	}
+
+	w.Header().Set("Location", "/documents/"+id+"/uploads/"+session.ID)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	w.Header().Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// GetUploadOffset handles HEAD /documents/{id}/uploads/{upload}, telling the
+// client where to resume
+func (h *DocumentHandler) GetUploadOffset(w http.ResponseWriter, r *http.Request) {
+	if !h.tusRequest(w, r) {
+		return
+	}
+
+	session, err := h.attachmentUsecase.GetUpload(r.Context(), r.PathValue("id"), r.PathValue("upload"))
+	if err != nil {
+		w.WriteHeader(statusForError(err))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(session.Length, 10))
+	w.Header().Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+}
+
+// AppendUpload handles PATCH /documents/{id}/uploads/{upload}. The chunk
+// must start at the current offset; the last one attaches the file to the
+// document.
+func (h *DocumentHandler) AppendUpload(w http.ResponseWriter, r *http.Request) {
+	if !h.tusRequest(w, r) {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != mediaTypeOffsetOctets {
+		httpError(w, r, "Content-Type must be "+mediaTypeOffsetOctets, http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		httpError(w, r, "Upload-Offset must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+
+	session, document, err := h.attachmentUsecase.AppendUpload(r.Context(), r.PathValue("id"), r.PathValue("upload"), offset, uploadBody{r.Body})
+	if session != nil {
+		// Also on failure, so the client knows how much was kept
+		w.Header().Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+		w.Header().Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpError(w, r, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.writeError(w, r, err)
+		return
+	}
+
+	if document != nil {
+		h.notify(r, document, "document.attachment_added")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TerminateUpload handles DELETE /documents/{id}/uploads/{upload} (tus
+// termination)
+func (h *DocumentHandler) TerminateUpload(w http.ResponseWriter, r *http.Request) {
+	if !h.tusRequest(w, r) {
+		return
+	}
+
+	if err := h.attachmentUsecase.TerminateUpload(r.Context(), r.PathValue("id"), r.PathValue("upload")); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tusRequest sets the common tus headers and rejects requests for another
+// protocol version. It reports whether the request may proceed.
+func (h *DocumentHandler) tusRequest(w http.ResponseWriter, r *http.Request) bool {
+	// Add security headers
+	h.addSecurityHeaders(w)
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	if h.attachmentUsecase == nil {
+		httpError(w, r, "Attachment uploads are not enabled", http.StatusNotImplemented)
+		return false
+	}
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		httpError(w, r, "Unsupported tus version", http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// parseUploadMetadata decodes a tus Upload-Metadata header: comma-separated
+// keys, each optionally followed by a space and a base64 value
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("Upload-Metadata has an empty key")
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("Upload-Metadata value of " + key + " is not base64")
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
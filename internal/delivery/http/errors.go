@@ -0,0 +1,83 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// statusForError maps domain errors to HTTP status codes
+func statusForError(err error) int {
+	switch {
+	// The store may be left inconsistent, whatever the original error was
+	case errors.Is(err, entity.ErrRollbackFailed):
+		return http.StatusInternalServerError
+	case errors.Is(err, entity.ErrDocumentNotFound),
+		errors.Is(err, entity.ErrRevisionNotFound),
+		errors.Is(err, entity.ErrAttachmentNotFound),
+		errors.Is(err, entity.ErrUploadNotFound),
+		errors.Is(err, entity.ErrUserNotFound),
+		errors.Is(err, entity.ErrContributorNotFound),
+		errors.Is(err, entity.ErrTemplateNotFound),
+		errors.Is(err, entity.ErrTagNotFound),
+		errors.Is(err, entity.ErrLockNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrInvalidDocumentID),
+		errors.Is(err, entity.ErrInvalidCursor),
+		errors.Is(err, entity.ErrInvalidPageLimit),
+		errors.Is(err, entity.ErrInvalidSearchQuery),
+		errors.Is(err, entity.ErrInvalidDocumentTitle),
+		errors.Is(err, entity.ErrInvalidDocumentVersion),
+		errors.Is(err, entity.ErrInvalidBatchOperation),
+		errors.Is(err, entity.ErrInvalidRevision),
+		errors.Is(err, entity.ErrInvalidVersionBump),
+		errors.Is(err, entity.ErrInvalidAttachment),
+		errors.Is(err, entity.ErrAttachmentNotUploaded),
+		errors.Is(err, entity.ErrInvalidUserID),
+		errors.Is(err, entity.ErrInvalidContributorRole),
+		errors.Is(err, entity.ErrInvalidImport),
+		errors.Is(err, entity.ErrInvalidTemplate),
+		errors.Is(err, entity.ErrInvalidTag),
+		errors.Is(err, entity.ErrInvalidGrant):
+		return http.StatusBadRequest
+	case errors.Is(err, entity.ErrDocumentVersionConflict),
+		errors.Is(err, entity.ErrDocumentNotDeleted),
+		errors.Is(err, entity.ErrDocumentAlreadyExists),
+		errors.Is(err, entity.ErrVersionRegression),
+		errors.Is(err, entity.ErrUploadOffsetMismatch),
+		errors.Is(err, entity.ErrUploadBusy),
+		errors.Is(err, entity.ErrContributorExists),
+		errors.Is(err, entity.ErrTemplateAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, entity.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, entity.ErrAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, entity.ErrDocumentLocked):
+		return http.StatusLocked
+	case errors.Is(err, entity.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, entity.ErrUploadExpired):
+		return http.StatusGone
+	case errors.Is(err, entity.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, entity.ErrBatchAborted):
+		return http.StatusFailedDependency
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeUsecaseError writes a domain error with its HTTP status code.
+// Unexpected errors are not echoed to the client.
+func writeUsecaseError(w http.ResponseWriter, r *http.Request, err error) {
+	status := statusForError(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("Error handling %s %s: %v", r.Method, r.URL.Path, err)
+		httpError(w, r, "Internal server error", status)
+		return
+	}
+	httpError(w, r, err.Error(), status)
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// fieldSet is a tree of selected JSON fields; a nil subtree selects the
+// whole value
+type fieldSet map[string]fieldSet
+
+// documentSchema lists the JSON fields a document projection may select
+var documentSchema = schemaOf(reflect.TypeOf(entity.DocumentJSON{}))
+
+// documentType is encoded through its JSON form, entity.DocumentJSON
+var documentType = reflect.TypeOf(entity.Document{})
+
+// parseFields parses "id,title,contributors.name" into a field set.
+// An empty string selects everything.
+func parseFields(raw string) (fieldSet, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	fields := fieldSet{}
+	for _, path := range strings.Split(raw, ",") {
+		node, schema := fields, documentSchema
+		parts := strings.Split(strings.TrimSpace(path), ".")
+		for i, part := range parts {
+			sub, known := schema[part]
+			if !known {
+				return nil, fmt.Errorf("unknown field %q", strings.Join(parts[:i+1], "."))
+			}
+			last := i == len(parts)-1
+			if last {
+				// Selecting a parent selects all of it
+				node[part] = nil
+				break
+			}
+			if sub == nil {
+				return nil, fmt.Errorf("field %q has no subfields", strings.Join(parts[:i+1], "."))
+			}
+			child, exists := node[part]
+			if exists && child == nil {
+				break
+			}
+			if !exists {
+				child = fieldSet{}
+				node[part] = child
+			}
+			node, schema = child, sub
+		}
+	}
+	return fields, nil
+}
+
+// project returns v reduced to the selected fields, ready to be encoded
+func project(v interface{}, fields fieldSet) interface{} {
+	if fields == nil {
+		return v
+	}
+	return projectValue(reflect.ValueOf(v), fields)
+}
+
+func projectValue(v reflect.Value, fields fieldSet) interface{} {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return projectValue(v.Elem(), fields)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = projectValue(v.Index(i), fields)
+		}
+		return items
+	case reflect.Struct:
+		if v.Type() == documentType {
+			document := v.Interface().(entity.Document)
+			v = reflect.ValueOf(document.JSON())
+		}
+		out := make(map[string]interface{}, len(fields))
+		projectStruct(v, fields, out)
+		return out
+	}
+	return v.Interface()
+}
+
+// projectStruct copies the selected fields of a struct into out, flattening
+// embedded structs the way encoding/json does
+func projectStruct(v reflect.Value, fields fieldSet, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, embedded := jsonName(field)
+		if embedded {
+			projectStruct(v.Field(i), fields, out)
+			continue
+		}
+		sub, selected := fields[name]
+		if !selected {
+			continue
+		}
+		if sub == nil {
+			out[name] = v.Field(i).Interface()
+		} else {
+			out[name] = projectValue(v.Field(i), sub)
+		}
+	}
+}
+
+// schemaOf returns the selectable JSON field tree of a type
+func schemaOf(t reflect.Type) fieldSet {
+	for !isJSONLeaf(t) && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isJSONLeaf(t) {
+		return nil
+	}
+
+	schema := fieldSet{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, embedded := jsonName(field)
+		if embedded {
+			for k, sub := range schemaOf(field.Type) {
+				schema[k] = sub
+			}
+			continue
+		}
+		if name != "" {
+			schema[name] = schemaOf(field.Type)
+		}
+	}
+	return schema
+}
+
+// jsonName returns the JSON name of a struct field, or reports that the
+// field is an embedded struct whose fields are promoted
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+		return "", true
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+// isJSONLeaf reports whether a type encodes itself, like time.Time
+func isJSONLeaf(t reflect.Type) bool {
+	marshaler := reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler := reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	return t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) ||
+		t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler)
+}
@@ -0,0 +1,346 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Content codings, in order of preference
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// incompressibleTypes are media types whose content is compressed already,
+// like most attachments; a trailing "/" matches a whole top-level type
+var incompressibleTypes = []string{
+	"image/", "audio/", "video/", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2",
+	"application/x-xz", "application/zstd", "application/x-7z-compressed",
+	"application/x-rar-compressed", "application/pdf",
+}
+
+// compressibleImages are image types stored as text
+var compressibleImages = []string{"image/svg+xml", "image/x-icon", "image/bmp"}
+
+// compressor is a pooled gzip or zlib writer
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compression compresses responses with gzip or deflate, as negotiated from
+// Accept-Encoding
+type Compression struct {
+	minSize int
+	pools   map[string]*sync.Pool
+}
+
+// NewCompression creates a new Compression instance. Responses shorter than
+// minSize bytes are sent as they are; level is a compress/flate level.
+func NewCompression(minSize, level int) (*Compression, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return nil, fmt.Errorf("invalid compression level %d", level)
+	}
+	return &Compression{
+		minSize: minSize,
+		pools: map[string]*sync.Pool{
+			encodingGzip: {New: func() interface{} {
+				w, _ := gzip.NewWriterLevel(io.Discard, level)
+				return w
+			}},
+			encodingDeflate: {New: func() interface{} {
+				w, _ := zlib.NewWriterLevel(io.Discard, level)
+				return w
+			}},
+		},
+	}, nil
+}
+
+// Middleware returns the compression middleware. Websocket handshakes,
+// HEAD and Range requests are passed through untouched, and so are
+// responses marked Cache-Control: no-transform. Compressed responses suffix
+// strong entity tags with their coding, which is removed from If-Match,
+// If-None-Match and If-Range so that handlers see their own tags.
+func (c *Compression) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWebSocketHandshake(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		for _, name := range []string{"If-Match", "If-None-Match", "If-Range"} {
+			// If-Range may hold a date instead
+			if list := r.Header.Get(name); strings.Contains(list, `"`) {
+				r.Header.Set(name, uncodedETags(list))
+			}
+		}
+		if r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			// The response does not vary for this client, but may for others
+			w.Header().Add("Vary", "Accept-Encoding")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, compression: c, encoding: encoding, ifNoneMatch: ifNoneMatch}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// or returns "" for an uncompressed response
+func negotiateEncoding(header string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		quality := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		qualities[coding] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+	return best
+}
+
+// codedETag returns the entity tag of a response compressed with a coding.
+// The compressed bytes differ from the identity ones, so a strong tag gets
+// the coding as a suffix, or If-Range could mix the two; weak tags are kept.
+func codedETag(etag, coding string) string {
+	if !strings.HasPrefix(etag, `"`) {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + coding + `"`
+}
+
+// uncodedETags removes the coding suffixes from a list of entity tags
+func uncodedETags(list string) string {
+	tags := strings.Split(list, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		for _, coding := range []string{encodingGzip, encodingDeflate} {
+			if base, ok := strings.CutSuffix(tag, "-"+coding+`"`); ok && strings.HasPrefix(tag, `"`) {
+				tag = base + `"`
+				break
+			}
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", ")
+}
+
+// hasETag reports whether a list of entity tags contains etag
+func hasETag(list, etag string) bool {
+	for _, tag := range strings.Split(list, ",") {
+		if strings.TrimSpace(tag) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDirective reports whether a Cache-Control header holds a directive
+func hasDirective(cacheControl, directive string) bool {
+	for _, part := range strings.Split(cacheControl, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressible reports whether a response may be compressed
+func compressible(header http.Header, status int) bool {
+	switch {
+	case status < http.StatusOK, status == http.StatusNoContent,
+		status == http.StatusPartialContent, status == http.StatusNotModified:
+		return false
+	case header.Get("Content-Encoding") != "", header.Get("Content-Range") != "":
+		return false
+	case hasDirective(header.Get("Cache-Control"), "no-transform"):
+		// The handler needs the bytes sent as they are, e.g. for ranges
+		return false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	for _, t := range compressibleImages {
+		if mediaType == t {
+			return true
+		}
+	}
+	for _, t := range incompressibleTypes {
+		if mediaType == t || strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// compressWriter holds back the start of a response until it is known to
+// be long enough to be worth compressing
+type compressWriter struct {
+	http.ResponseWriter
+	compression *Compression
+	encoding    string
+	// ifNoneMatch is the request's If-None-Match as the client sent it
+	ifNoneMatch string
+
+	status  int
+	buf     []byte
+	decided bool
+	writer  compressor
+}
+
+// WriteHeader records the status; it is sent once the body is decided on
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 || cw.decided {
+		return
+	}
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.status = status
+	if !compressible(cw.Header(), status) {
+		cw.decide(false)
+	} else if length, err := strconv.Atoi(cw.Header().Get("Content-Length")); err == nil && length < cw.compression.minSize {
+		cw.decide(false)
+	}
+}
+
+// Write buffers the body until it reaches the size threshold
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.writer != nil {
+			return cw.writer.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.compression.minSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what was written so far. A response flushed before reaching
+// the threshold is taken to be a stream, and compressed.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.status == 0 {
+			cw.WriteHeader(http.StatusOK)
+		}
+		if err := cw.decide(true); err != nil {
+			return
+		}
+	}
+	if cw.writer != nil {
+		if err := cw.writer.Flush(); err != nil {
+			return
+		}
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap gives http.ResponseController access to the connection
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// decide sends the status line, compressing the body if it is large and
+// of a compressible type, and then the buffered start of the body
+func (cw *compressWriter) decide(large bool) error {
+	cw.decided = true
+	header := cw.Header()
+	if cw.status == http.StatusNotModified {
+		header.Add("Vary", "Accept-Encoding")
+		// The client may hold a compressed copy; confirm the tag it has
+		if etag := codedETag(header.Get("ETag"), cw.encoding); etag != "" && hasETag(cw.ifNoneMatch, etag) {
+			header.Set("ETag", etag)
+		}
+	} else if compressible(header, cw.status) {
+		header.Add("Vary", "Accept-Encoding")
+		if large {
+			// Content sniffing would see the compressed bytes
+			if header.Get("Content-Type") == "" {
+				header.Set("Content-Type", http.DetectContentType(cw.buf))
+			}
+			if etag := header.Get("ETag"); etag != "" {
+				header.Set("ETag", codedETag(etag, cw.encoding))
+			}
+			header.Del("Content-Length")
+			header.Set("Content-Encoding", cw.encoding)
+			cw.writer = cw.compression.pools[cw.encoding].Get().(compressor)
+			cw.writer.Reset(cw.ResponseWriter)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.writer != nil {
+		_, err := cw.writer.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// close ends the response once the handler returns
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		// Nothing was written: let net/http send its default response
+		if cw.status == 0 {
+			return
+		}
+		cw.decide(false)
+	}
+	if cw.writer != nil {
+		cw.writer.Close()
+		cw.compression.pools[cw.encoding].Put(cw.writer)
+		cw.writer = nil
+	}
+}
@@ -0,0 +1,408 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testMinSize is the threshold of the compression under test
+const testMinSize = 100
+
+// serve runs a handler behind the compression middleware
+func serve(t *testing.T, r *http.Request, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	compression, err := NewCompression(testMinSize, gzip.DefaultCompression)
+	if err != nil {
+		t.Fatalf("NewCompression: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	compression.Middleware(handler).ServeHTTP(rec, r)
+	return rec
+}
+
+// request returns a GET request accepting the given codings
+func request(acceptEncoding string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/documents", nil)
+	if acceptEncoding != "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	return r
+}
+
+// body returns the decoded body of a response
+func body(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var reader io.Reader = rec.Body
+	switch coding := rec.Header().Get("Content-Encoding"); coding {
+	case "":
+	case encodingGzip:
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("gzip: %v", err)
+		}
+		reader = zr
+	case encodingDeflate:
+		zr, err := zlib.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("zlib: %v", err)
+		}
+		reader = zr
+	default:
+		t.Fatalf("unexpected Content-Encoding %q", coding)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	return string(data)
+}
+
+// hasVary reports whether a response varies on a request header
+func hasVary(rec *httptest.ResponseRecorder, field string) bool {
+	for _, value := range rec.Header().Values("Vary") {
+		for _, listed := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// text returns a handler writing content of a type in the given chunks
+func text(contentType string, chunks ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		for _, chunk := range chunks {
+			io.WriteString(w, chunk)
+		}
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", encodingGzip},
+		{"deflate", encodingDeflate},
+		{"GZIP", encodingGzip},
+		{"deflate, gzip", encodingGzip},
+		{"gzip;q=0.5, deflate", encodingDeflate},
+		{"gzip; q=0.5, deflate;q=0.8", encodingDeflate},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"gzip;q=0, *", encodingDeflate},
+		{"*", encodingGzip},
+		{"*;q=0", ""},
+		{"br, zstd", ""},
+		{"identity", ""},
+		{"gzip;q=abc, deflate", encodingDeflate},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompressionThreshold(t *testing.T) {
+	short := strings.Repeat("a", testMinSize-1)
+	long := strings.Repeat("a", testMinSize)
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		encoding string
+		want     string
+	}{
+		{"short body", text("text/plain", short), "", short},
+		{"body at the threshold", text("text/plain", long), encodingGzip, long},
+		{"body reaching the threshold over several writes", text("text/plain", short, "b"), encodingGzip, short + "b"},
+		{
+			"short Content-Length",
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Length", "10")
+				w.WriteHeader(http.StatusOK)
+				io.WriteString(w, "0123456789")
+			},
+			"", "0123456789",
+		},
+		{"empty body", text("text/plain"), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, request("gzip"), tt.handler)
+			if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Content-Encoding %q, want %q", got, tt.encoding)
+			}
+			if got := body(t, rec); got != tt.want {
+				t.Errorf("body %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressionCodings(t *testing.T) {
+	content := strings.Repeat("document ", 50)
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		t.Run(coding, func(t *testing.T) {
+			rec := serve(t, request(coding), func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Length", "450")
+				io.WriteString(w, content)
+			})
+			if got := rec.Header().Get("Content-Encoding"); got != coding {
+				t.Fatalf("Content-Encoding %q, want %q", got, coding)
+			}
+			if rec.Header().Get("Content-Length") != "" {
+				t.Error("Content-Length of the uncompressed body was kept")
+			}
+			if !hasVary(rec, "Accept-Encoding") {
+				t.Error("missing Vary: Accept-Encoding")
+			}
+			if rec.Body.Len() >= len(content) {
+				t.Errorf("compressed to %d bytes from %d", rec.Body.Len(), len(content))
+			}
+			if got := body(t, rec); got != content {
+				t.Errorf("body %q, want %q", got, content)
+			}
+		})
+	}
+}
+
+func TestCompressionWithoutAcceptedCoding(t *testing.T) {
+	content := strings.Repeat("a", 2*testMinSize)
+	rec := serve(t, request("br"), text("text/plain", content))
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding %q", got)
+	}
+	if !hasVary(rec, "Accept-Encoding") {
+		t.Error("missing Vary: Accept-Encoding")
+	}
+	if got := rec.Body.String(); got != content {
+		t.Errorf("body %q, want %q", got, content)
+	}
+}
+
+func TestCompressionFlushStreams(t *testing.T) {
+	rec := serve(t, request("gzip"), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, "{\"id\":1}\n")
+		http.NewResponseController(w).Flush()
+
+		// A flush sends the response even though it is short
+		if !recorder(w).Flushed {
+			t.Error("response was not flushed")
+		}
+		if got := recorder(w).Header().Get("Content-Encoding"); got != encodingGzip {
+			t.Errorf("Content-Encoding %q when flushed, want gzip", got)
+		}
+		if recorder(w).Body.Len() == 0 {
+			t.Error("nothing was sent on flush")
+		}
+		io.WriteString(w, "{\"id\":2}\n")
+	})
+	if got := body(t, rec); got != "{\"id\":1}\n{\"id\":2}\n" {
+		t.Errorf("body %q", got)
+	}
+}
+
+// recorder returns the recorder a compressed response is written to
+func recorder(w http.ResponseWriter) *httptest.ResponseRecorder {
+	return w.(*compressWriter).ResponseWriter.(*httptest.ResponseRecorder)
+}
+
+func TestCompressionSkipsIncompressibleResponses(t *testing.T) {
+	content := strings.Repeat("a", 2*testMinSize)
+	tests := []struct {
+		name    string
+		request *http.Request
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"image", request("gzip"), text("image/png", content), ""},
+		{"video", request("gzip"), text("video/mp4", content), ""},
+		{"archive", request("gzip"), text("application/zip", content), ""},
+		{"pdf with parameters", request("gzip"), text("application/pdf; name=x", content), ""},
+		{"svg image", request("gzip"), text("image/svg+xml", content), encodingGzip},
+		{"sniffed type", request("gzip"), text("", content), encodingGzip},
+		{
+			"no-transform",
+			request("gzip"),
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Header().Set("Cache-Control", "private, no-cache, no-transform")
+				io.WriteString(w, content)
+			},
+			"",
+		},
+		{
+			"already encoded",
+			request("gzip"),
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Content-Encoding", "br")
+				io.WriteString(w, content)
+			},
+			"br",
+		},
+		{
+			"no content",
+			request("gzip"),
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			"",
+		},
+		{
+			"range request",
+			func() *http.Request {
+				r := request("gzip")
+				r.Header.Set("Range", "bytes=0-")
+				return r
+			}(),
+			text("text/plain", content),
+			"",
+		},
+		{
+			"HEAD request",
+			func() *http.Request {
+				r := request("gzip")
+				r.Method = http.MethodHead
+				return r
+			}(),
+			text("text/plain", content),
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, tt.request, tt.handler)
+			if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+				t.Errorf("Content-Encoding %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && rec.Body.String() != "" && rec.Body.String() != content {
+				t.Errorf("body was changed to %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompressionSniffsBeforeCompressing(t *testing.T) {
+	rec := serve(t, request("gzip"), text("", "<!DOCTYPE html>"+strings.Repeat(" ", testMinSize)))
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("Content-Type %q, want the type of the uncompressed body", got)
+	}
+}
+
+// conditional returns a handler answering 304 when If-None-Match holds its
+// tag, as the document handlers do
+func conditional(etag, content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		if hasETag(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, content)
+	}
+}
+
+func TestCompressionCodedETags(t *testing.T) {
+	content := strings.Repeat("a", 2*testMinSize)
+	tests := []struct {
+		name   string
+		etag   string
+		accept string
+		want   string
+	}{
+		{"strong tag of a compressed response", `"abc"`, "gzip", `"abc-gzip"`},
+		{"strong tag of a deflated response", `"abc"`, "deflate", `"abc-deflate"`},
+		{"weak tag", `W/"abc"`, "gzip", `W/"abc"`},
+		{"uncompressed response", `"abc"`, "", `"abc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, request(tt.accept), conditional(tt.etag, content))
+			if got := rec.Header().Get("ETag"); got != tt.want {
+				t.Errorf("ETag %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressionNotModified(t *testing.T) {
+	content := strings.Repeat("a", 2*testMinSize)
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        string
+	}{
+		// The client holds the compressed copy and gets its tag confirmed
+		{"coded tag", `"abc-gzip"`, `"abc-gzip"`},
+		// The client holds an uncompressed copy, e.g. from a HEAD request
+		{"plain tag", `"abc"`, `"abc"`},
+		{"coded tag among others", `"old", "abc-gzip"`, `"abc-gzip"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := request("gzip")
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := serve(t, r, conditional(`"abc"`, content))
+			if rec.Code != http.StatusNotModified {
+				t.Fatalf("status %d, want 304", rec.Code)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.want {
+				t.Errorf("ETag %s, want %s", got, tt.want)
+			}
+			if !hasVary(rec, "Accept-Encoding") {
+				t.Error("missing Vary: Accept-Encoding")
+			}
+			if rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 0 {
+				t.Error("304 has a coded body")
+			}
+		})
+	}
+}
+
+func TestCompressionRewritesConditionalHeaders(t *testing.T) {
+	tests := []struct {
+		header string
+		sent   string
+		want   string
+	}{
+		{"If-Match", `"a-gzip"`, `"a"`},
+		{"If-Match", `"a-gzip", "b-deflate",  "c"`, `"a", "b", "c"`},
+		{"If-Match", `*`, `*`},
+		{"If-None-Match", `"a-deflate"`, `"a"`},
+		// Weak tags are never coded
+		{"If-None-Match", `W/"a-gzip"`, `W/"a-gzip"`},
+		{"If-None-Match", `"a-xml-gzip"`, `"a-xml"`},
+		{"If-Range", `"a-gzip"`, `"a"`},
+		// If-Range may hold a date, which is left alone
+		{"If-Range", "Wed, 21 Oct 2015 07:28:00 GMT", "Wed, 21 Oct 2015 07:28:00 GMT"},
+	}
+	for _, tt := range tests {
+		// Requests are rewritten whether or not their response is compressed
+		for _, accept := range []string{"gzip", ""} {
+			r := request(accept)
+			r.Header.Set(tt.header, tt.sent)
+			var got string
+			serve(t, r, func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get(tt.header)
+			})
+			if got != tt.want {
+				t.Errorf("%s: %s with Accept-Encoding %q reached the handler as %s, want %s", tt.header, tt.sent, accept, got, tt.want)
+			}
+		}
+	}
+}
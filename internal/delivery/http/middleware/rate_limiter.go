@@ -12,6 +12,14 @@ type RateLimiter struct {
 	mutex    sync.RWMutex
 	limit    int
 	window   time.Duration
+	buckets  []bucket
+}
+
+// bucket counts matching requests apart from the others, with its own limit
+type bucket struct {
+	name  string
+	match func(r *http.Request) bool
+	limit int
 }
 
 // NewRateLimiter creates a new RateLimiter
@@ -28,6 +36,15 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 	return rl
 }
 
+// WithLimit gives the requests matched by match a separate budget of limit
+// requests per window, e.g. the chunks of a resumable upload, which would
+// otherwise exhaust the budget of a client in a single upload. The first
+// matching bucket applies.
+func (rl *RateLimiter) WithLimit(name string, match func(r *http.Request) bool, limit int) *RateLimiter {
+	rl.buckets = append(rl.buckets, bucket{name: name, match: match, limit: limit})
+	return rl
+}
+
 // Middleware returns the rate limiting middleware
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -40,7 +57,15 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 			clientIP = realIP
 		}
 
-		if !rl.allowRequest(clientIP) {
+		key, limit := clientIP, rl.limit
+		for _, b := range rl.buckets {
+			if b.match(r) {
+				key, limit = b.name+"|"+clientIP, b.limit
+				break
+			}
+		}
+
+		if !rl.allowRequest(key, limit) {
 			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 			return
 		}
@@ -49,16 +74,16 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
-// allowRequest checks if the request is allowed
-func (rl *RateLimiter) allowRequest(clientIP string) bool {
+// allowRequest checks if the request is allowed under the budget of key
+func (rl *RateLimiter) allowRequest(key string, limit int) bool {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
 	now := time.Now()
 	cutoff := now.Add(-rl.window)
 
-	// Get existing requests for this IP
-	requests := rl.requests[clientIP]
+	// Get existing requests for this client
+	requests := rl.requests[key]
 
 	// Filter requests within the time window
 	var validRequests []time.Time
@@ -69,13 +94,13 @@ func (rl *RateLimiter) allowRequest(clientIP string) bool {
 	}
 
 	// Check if it exceeds the limit
-	if len(validRequests) >= rl.limit {
+	if len(validRequests) >= limit {
 		return false
 	}
 
 	// Add the new request
 	validRequests = append(validRequests, now)
-	rl.requests[clientIP] = validRequests
+	rl.requests[key] = validRequests
 
 	return true
 }
@@ -1,16 +1,35 @@
 package middleware
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+
+	"frontend-challenge/internal/domain/entity"
 )
 
+// ActorBinder makes the authenticated user of a request known to the
+// layers handling it, e.g. as revision author
+type ActorBinder interface {
+	BindActor(ctx context.Context, user entity.User) context.Context
+}
+
 // RequestValidator implements request size validation
 type RequestValidator struct {
 	maxBodySize int64
+	bodyLimits  []bodyLimit
+	actors      ActorBinder
+	// webSocketRoutes matches the websocket endpoints
+	webSocketRoutes func(r *http.Request) bool
+}
+
+// bodyLimit overrides the maximum body size for matching requests
+type bodyLimit struct {
+	match       func(r *http.Request) bool
+	maxBodySize int64
 }
 
 // NewRequestValidator creates a new RequestValidator
@@ -26,31 +45,88 @@ func isWebSocketHandshake(r *http.Request) bool {
 	return strings.Contains(conn, "upgrade") && upg == "websocket"
 }
 
+// WithBodyLimit sets a different maximum body size for the requests
+// matched by match, e.g. file uploads. The first matching limit applies.
+func (rv *RequestValidator) WithBodyLimit(match func(r *http.Request) bool, maxBodySize int64) *RequestValidator {
+	rv.bodyLimits = append(rv.bodyLimits, bodyLimit{match: match, maxBodySize: maxBodySize})
+	return rv
+}
+
+// WithActors binds the authenticated user of every request to its context
+func (rv *RequestValidator) WithActors(actors ActorBinder) *RequestValidator {
+	rv.actors = actors
+	return rv
+}
+
+// WithWebSocketRoutes names the websocket endpoints. Browsers cannot set
+// headers on a websocket handshake, so handshakes on those may carry the
+// credentials as ?auth=base64(user-name:user-id) instead of the
+// Authorization header.
+func (rv *RequestValidator) WithWebSocketRoutes(match func(r *http.Request) bool) *RequestValidator {
+	rv.webSocketRoutes = match
+	return rv
+}
+
+// authorization returns the credentials of a request
+func (rv *RequestValidator) authorization(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if auth == "" && rv.webSocketRoutes != nil && isWebSocketHandshake(r) && rv.webSocketRoutes(r) {
+		if token := r.URL.Query().Get("auth"); token != "" {
+			auth = "Basic " + token
+		}
+	}
+	return auth
+}
+
+// bindActor attaches the authenticated user to the request context
+func (rv *RequestValidator) bindActor(r *http.Request, userName, userID string) *http.Request {
+	if rv.actors == nil {
+		return r
+	}
+	return r.WithContext(rv.actors.BindActor(r.Context(), *entity.NewUser(userID, userName)))
+}
+
+// bodySizeFor returns the maximum body size of a request
+func (rv *RequestValidator) bodySizeFor(r *http.Request) int64 {
+	for _, limit := range rv.bodyLimits {
+		if limit.match(r) {
+			return limit.maxBodySize
+		}
+	}
+	return rv.maxBodySize
+}
+
 // Middleware returns the request validation middleware
 func (rv *RequestValidator) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Validate body size
-		if r.ContentLength > rv.maxBodySize {
+		maxBodySize := rv.bodySizeFor(r)
+		if r.ContentLength > maxBodySize {
 			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
 			return
 		}
 
 		// Limit the size of the request body read
-		r.Body = http.MaxBytesReader(w, r.Body, rv.maxBodySize)
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
 
-		if isWebSocketHandshake(r) {
-			next.ServeHTTP(w, r)
-			return
-		}
+		// Identity headers are only ever set from the Authorization header
+		r.Header.Del("user-name")
+		r.Header.Del("user-id")
 
 		// Validate important headers
-		if !rv.validateHeaders(r) {
+		if !rv.validateHeaders(r, maxBodySize) {
 			http.Error(w, "Invalid headers", http.StatusBadRequest)
 			return
 		}
 
+		// Browsers send CORS preflight requests without credentials
+		if isPreflight(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Authorization: Basic base64(user-name:user-id)
-		auth := r.Header.Get("Authorization")
+		auth := rv.authorization(r)
 		if auth == "" {
 			http.Error(w, "Authorization header is required", http.StatusBadRequest)
 			return
@@ -65,21 +141,29 @@ func (rv *RequestValidator) Middleware(next http.Handler) http.Handler {
 		r.Header.Set("user-name", userName)
 		r.Header.Set("user-id", userID)
 
+		// Make the caller known to the use cases
+		r = rv.bindActor(r, userName, userID)
+
 		next.ServeHTTP(w, r)
 	})
 }
 
+// isPreflight reports whether r is a CORS preflight request
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
 // validateHeaders validates important headers
-func (rv *RequestValidator) validateHeaders(r *http.Request) bool {
+func (rv *RequestValidator) validateHeaders(r *http.Request, maxBodySize int64) bool {
 	// Validate Content-Length if present
 	if contentLength := r.Header.Get("Content-Length"); contentLength != "" {
-		if length, err := strconv.ParseInt(contentLength, 10, 64); err != nil || length > rv.maxBodySize {
+		if length, err := strconv.ParseInt(contentLength, 10, 64); err != nil || length > maxBodySize {
 			return false
 		}
 	}
 
 	// Validate Content-Type for requests with a body
-	if r.Method != "GET" && r.Method != "HEAD" && r.Method != "DELETE" {
+	if r.Method != "GET" && r.Method != "HEAD" && r.Method != "DELETE" && r.ContentLength != 0 {
 		contentType := r.Header.Get("Content-Type")
 		if contentType == "" {
 			return false
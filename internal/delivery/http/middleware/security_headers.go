@@ -40,7 +40,7 @@ func (sh *SecurityHeaders) Middleware(next http.Handler) http.Handler {
 
 		// Restrictive CORS (should be configured per domain)
 		w.Header().Set("Access-Control-Allow-Origin", "*") // TODO: Restrict in production
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -48,7 +47,7 @@ func (h *SecurityHandler) GetSecurityStats(w http.ResponseWriter, r *http.Reques
 	rateLimitStats := h.rateLimiter.GetStats()
 	logStats, err := h.logRotator.GetLogStats()
 	if err != nil {
-		http.Error(w, "Error getting log statistics", http.StatusInternalServerError)
+		httpError(w, r, "Error getting log statistics", http.StatusInternalServerError)
 		return
 	}
 	cacheStats := h.cache.GetStats()
@@ -62,11 +61,7 @@ func (h *SecurityHandler) GetSecurityStats(w http.ResponseWriter, r *http.Reques
 		"timestamp":   time.Now().Format(time.RFC3339),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(stats); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-		return
-	}
+	writeResponse(w, r, http.StatusOK, stats)
 }
 
 // addSecurityHeaders adds security headers
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/usecase"
+)
+
+// TemplateHandler handles HTTP requests for document templates
+type TemplateHandler struct {
+	templateUsecase *usecase.TemplateUsecase
+}
+
+// NewTemplateHandler creates a new TemplateHandler instance
+func NewTemplateHandler(templateUsecase *usecase.TemplateUsecase) *TemplateHandler {
+	return &TemplateHandler{
+		templateUsecase: templateUsecase,
+	}
+}
+
+// GetTemplates handles GET /templates
+func (h *TemplateHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	templates, err := h.templateUsecase.GetTemplates(r.Context())
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, templates)
+}
+
+// GetTemplate handles GET /templates/{id}
+func (h *TemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	template, err := h.templateUsecase.GetTemplate(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, template)
+}
+
+// CreateTemplate handles POST /templates
+func (h *TemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	var template entity.Template
+	if err := decodeRequest(r, &template); err != nil {
+		writeDecodeError(w, r, "Error decoding template", err)
+		return
+	}
+
+	if err := h.templateUsecase.CreateTemplate(r.Context(), &template); err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+	writeResponse(w, r, http.StatusCreated, &template)
+}
+
+// UpdateTemplate handles PUT /templates/{id}
+func (h *TemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	var template entity.Template
+	if err := decodeRequest(r, &template); err != nil {
+		writeDecodeError(w, r, "Error decoding template", err)
+		return
+	}
+
+	id := r.PathValue("id")
+	if template.ID != "" && template.ID != id {
+		httpError(w, r, "template id does not match the request path", http.StatusBadRequest)
+		return
+	}
+	template.ID = id
+
+	if err := h.templateUsecase.UpdateTemplate(r.Context(), &template); err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, &template)
+}
+
+// DeleteTemplate handles DELETE /templates/{id}
+func (h *TemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	if err := h.templateUsecase.DeleteTemplate(r.Context(), r.PathValue("id")); err != nil {
+		writeUsecaseError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addSecurityHeaders adds security headers
+func (h *TemplateHandler) addSecurityHeaders(w http.ResponseWriter) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("X-XSS-Protection", "1; mode=block")
+	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+	w.Header().Set("Access-Control-Allow-Origin", "*") // TODO: Restrict in production
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+}
@@ -0,0 +1,358 @@
+package http
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/pkg/jsonvalue"
+)
+
+// XML elements mapping JSON values
+const (
+	xmlRoot  = "response"
+	xmlItem  = "item"
+	xmlEntry = "entry"
+	xmlKey   = "key"
+)
+
+// maxXMLDepth bounds the nesting of decoded XML elements
+const maxXMLDepth = 512
+
+// xmlCodec encodes bodies as XML. The JSON form of a value is written
+// under a <response> root: object members become elements named after
+// their keys, or <entry key="..."> when the key is not an XML name, and
+// array items become <item> elements. Decoding reads the same shape, with
+// the target type telling numbers, booleans and arrays apart.
+type xmlCodec struct{}
+
+func (xmlCodec) mediaTypes() []string { return []string{"application/xml", "text/xml"} }
+
+func (xmlCodec) tagSuffix() string { return "xml" }
+
+func (xmlCodec) marshal(v interface{}) ([]byte, error) {
+	value, err := jsonvalue.Of(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	if err := encodeXML(encoder, xml.StartElement{Name: xml.Name{Local: xmlRoot}}, value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (xmlCodec) newDecoder(r io.Reader) bodyDecoder { return &xmlDecoder{r: r} }
+
+// encodeXML writes a JSON value as the content of an element
+func encodeXML(encoder *xml.Encoder, start xml.StartElement, value interface{}) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case nil:
+	case bool:
+		if err := encoder.EncodeToken(xml.CharData(strconv.FormatBool(v))); err != nil {
+			return err
+		}
+	case json.Number:
+		if err := encoder.EncodeToken(xml.CharData(v)); err != nil {
+			return err
+		}
+	case string:
+		if err := encoder.EncodeToken(xml.CharData(v)); err != nil {
+			return err
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := encodeXML(encoder, xml.StartElement{Name: xml.Name{Local: xmlItem}}, item); err != nil {
+				return err
+			}
+		}
+	case jsonvalue.Object:
+		for _, member := range v {
+			if err := encodeXML(encoder, memberElement(member.Key), member.Value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %T", jsonvalue.ErrInvalidValue, value)
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// memberElement returns the element of an object member
+func memberElement(key string) xml.StartElement {
+	if isXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: xmlEntry},
+		Attr: []xml.Attr{{Name: xml.Name{Local: xmlKey}, Value: key}},
+	}
+}
+
+// isXMLName reports whether a key can be used as an element name as it is.
+// Names are kept to letters, digits, '_', '-' and '.', without a namespace
+// prefix or the reserved "xml" start.
+func isXMLName(key string) bool {
+	if key == "" || strings.HasPrefix(strings.ToLower(key), "xml") {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case unicode.IsLetter(c) || c == '_':
+		case i > 0 && (unicode.IsDigit(c) || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// xmlNode is a decoded element
+type xmlNode struct {
+	// key is the element name, or the key attribute of an entry
+	key      string
+	text     strings.Builder
+	children []*xmlNode
+}
+
+// xmlDecoder decodes an XML body into the JSON value its target type asks
+// for, and that as JSON into the target
+type xmlDecoder struct {
+	r      io.Reader
+	strict bool
+}
+
+// Decode decodes the body into v. An empty body is io.EOF, like in JSON.
+func (d *xmlDecoder) Decode(v interface{}) error {
+	root, err := parseXML(xml.NewDecoder(d.r))
+	if err != nil {
+		return err
+	}
+	return decodeJSONValue(xmlValue(root, reflect.TypeOf(v)), v, d.strict)
+}
+
+// DisallowUnknownFields rejects elements no struct field takes
+func (d *xmlDecoder) DisallowUnknownFields() { d.strict = true }
+
+// parseXML reads the root element of a document
+func parseXML(decoder *xml.Decoder) (*xmlNode, error) {
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF && len(stack) == 0 {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) >= maxXMLDepth {
+				return nil, fmt.Errorf("XML syntax error: elements nested too deeply")
+			}
+			node := &xmlNode{key: t.Name.Local}
+			if t.Name.Local == xmlEntry {
+				for _, attr := range t.Attr {
+					if attr.Name.Local == xmlKey {
+						node.key = attr.Value
+					}
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return readXMLTrailer(decoder, node)
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("XML syntax error: text outside of the root element")
+			}
+		}
+	}
+}
+
+// readXMLTrailer checks that only whitespace, comments and processing
+// instructions follow the root element
+func readXMLTrailer(decoder *xml.Decoder, root *xmlNode) (*xmlNode, error) {
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			return nil, fmt.Errorf("XML syntax error: data after the root element")
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return nil, fmt.Errorf("XML syntax error: data after the root element")
+			}
+		}
+	}
+}
+
+// xmlValue returns the JSON value of an element for a target type. Types
+// unknown to the decoder take objects for elements with children, arrays
+// for elements with <item> children only, and strings otherwise.
+func xmlValue(node *xmlNode, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == documentType {
+		t = reflect.TypeOf(entity.DocumentJSON{})
+	}
+	text := node.text.String()
+
+	switch {
+	case t == nil || t.Kind() == reflect.Interface:
+		switch {
+		case len(node.children) == 0:
+			return text
+		case node.itemsOnly():
+			return xmlItems(node, nil)
+		default:
+			return xmlObject(node, func(string) reflect.Type { return nil })
+		}
+	case decodesJSON(t) && len(node.children) == 0:
+		// Like time.Time, or attachments given by name
+		return text
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := jsonFieldTypes(t)
+		return xmlObject(node, func(key string) reflect.Type {
+			if field, ok := fields[key]; ok {
+				return field
+			}
+			// Like encoding/json, match names without regard to case
+			for name, field := range fields {
+				if strings.EqualFold(name, key) {
+					return field
+				}
+			}
+			return nil
+		})
+	case reflect.Map:
+		return xmlObject(node, func(string) reflect.Type { return t.Elem() })
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Bytes are base64 text, as in JSON
+			return strings.TrimSpace(text)
+		}
+		return xmlItems(node, t.Elem())
+	case reflect.Bool:
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil
+		}
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+		return text
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil
+		}
+		if jsonvalue.IsNumber(text) {
+			return json.Number(text)
+		}
+		// Not a number: leave it to encoding/json to report
+		return text
+	default:
+		return text
+	}
+}
+
+// decodesJSON reports whether a type decodes itself from JSON; its
+// elements may hold text or, as for the struct it is, children
+func decodesJSON(t reflect.Type) bool {
+	unmarshaler := reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler := reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	return reflect.PointerTo(t).Implements(unmarshaler) || reflect.PointerTo(t).Implements(textUnmarshaler)
+}
+
+// itemsOnly reports whether all children are array items
+func (n *xmlNode) itemsOnly() bool {
+	for _, child := range n.children {
+		if child.key != xmlItem {
+			return false
+		}
+	}
+	return true
+}
+
+// xmlItems returns the children of an element as an array
+func xmlItems(node *xmlNode, elem reflect.Type) []interface{} {
+	items := make([]interface{}, len(node.children))
+	for i, child := range node.children {
+		items[i] = xmlValue(child, elem)
+	}
+	return items
+}
+
+// xmlObject returns the children of an element as an object, typing each
+// member by its key
+func xmlObject(node *xmlNode, typeOf func(key string) reflect.Type) jsonvalue.Object {
+	object := make(jsonvalue.Object, len(node.children))
+	for i, child := range node.children {
+		object[i] = jsonvalue.Member{Key: child.key, Value: xmlValue(child, typeOf(child.key))}
+	}
+	return object
+}
+
+// jsonFieldTypes returns the types of the JSON fields of a struct type,
+// including the fields promoted from embedded structs
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, embedded := jsonName(field)
+		if embedded {
+			for k, sub := range jsonFieldTypes(field.Type) {
+				if _, ok := fields[k]; !ok {
+					fields[k] = sub
+				}
+			}
+			continue
+		}
+		if name != "" {
+			fields[name] = field.Type
+		}
+	}
+	return fields
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"context"
 	"sync"
 
 	"frontend-challenge/internal/domain/entity"
@@ -13,23 +14,37 @@ type Notifier interface {
 	BroadcastNotification(notification *entity.Notification)
 }
 
+// Audience decides who may be told about changes to a document
+type Audience interface {
+	CanRead(ctx context.Context, userID, documentID string) bool
+}
+
 // Hub gestiona conexiones WebSocket y difunde mensajes
 type Hub struct {
-	mu    sync.RWMutex
-	conns map[*websocket.Conn]bool
+	mu sync.RWMutex
+	// conns maps each connection to the ID of its user
+	conns    map[*websocket.Conn]string
+	audience Audience
 }
 
 // NewHub creates a new Hub instance
 func NewHub() *Hub {
 	return &Hub{
-		conns: make(map[*websocket.Conn]bool),
+		conns: make(map[*websocket.Conn]string),
 	}
 }
 
-// Register adds a connection to the hub
-func (h *Hub) Register(conn *websocket.Conn) {
+// WithAudience restricts notifications about documents to the users who may
+// read them
+func (h *Hub) WithAudience(audience Audience) *Hub {
+	h.audience = audience
+	return h
+}
+
+// Register adds a connection of the given user to the hub
+func (h *Hub) Register(conn *websocket.Conn, userID string) {
 	h.mu.Lock()
-	h.conns[conn] = true
+	h.conns[conn] = userID
 	h.mu.Unlock()
 }
 
@@ -40,11 +55,20 @@ func (h *Hub) Unregister(conn *websocket.Conn) {
 	h.mu.Unlock()
 }
 
-// BroadcastNotification sends the notification to all active connections
+// BroadcastNotification sends the notification to all active connections,
+// or only to those of its recipient when it has one. With an audience, users
+// only receive notifications about documents they may read.
 func (h *Hub) BroadcastNotification(notification *entity.Notification) {
 	h.mu.RLock()
-	for conn := range h.conns {
-		if err := conn.WriteJSON(notification); err != nil {
+	for conn, userID := range h.conns {
+		if notification.RecipientID != "" && notification.RecipientID != userID {
+			continue
+		}
+		visible := h.visibleTo(userID, notification)
+		if visible == nil {
+			continue
+		}
+		if err := conn.WriteJSON(visible); err != nil {
 			// If write fails, disconnect
 			h.mu.RUnlock()
 			h.mu.Lock()
@@ -56,3 +80,31 @@ func (h *Hub) BroadcastNotification(notification *entity.Notification) {
 	}
 	h.mu.RUnlock()
 }
+
+// visibleTo returns the notification as the user may see it, or nil if the
+// user may not see it at all. Notifications covering several documents
+// only list those the user may read.
+func (h *Hub) visibleTo(userID string, notification *entity.Notification) *entity.Notification {
+	if h.audience == nil {
+		return notification
+	}
+	ctx := context.Background()
+	if notification.DocumentID != "" && !h.audience.CanRead(ctx, userID, notification.DocumentID) {
+		return nil
+	}
+	if len(notification.DocumentIDs) == 0 {
+		return notification
+	}
+	var readable []string
+	for _, id := range notification.DocumentIDs {
+		if h.audience.CanRead(ctx, userID, id) {
+			readable = append(readable, id)
+		}
+	}
+	if len(readable) == 0 {
+		return nil
+	}
+	visible := *notification
+	visible.DocumentIDs = readable
+	return &visible
+}
@@ -0,0 +1,186 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/usecase"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxLiveMessageSize bounds a message sent by a live editing client
+const maxLiveMessageSize = 64 * 1024
+
+// liveRequest is a message sent by a live editing client
+type liveRequest struct {
+	Type string                 `json:"type"`
+	Ops  usecase.LiveOperations `json:"ops"`
+}
+
+// LiveHandler handles WebSocket connections for live document editing
+type LiveHandler struct {
+	liveUsecase *usecase.LiveUsecase
+	upgrader    websocket.Upgrader
+	notifier    Notifier
+	// allowedOrigins may open sessions besides the server's own origin
+	allowedOrigins []string
+}
+
+// NewLiveHandler creates a new LiveHandler instance. Documents stored from
+// live sessions are announced through the notifier.
+func NewLiveHandler(liveUsecase *usecase.LiveUsecase, notifier Notifier) *LiveHandler {
+	h := &LiveHandler{
+		liveUsecase: liveUsecase,
+		notifier:    notifier,
+	}
+	h.upgrader.CheckOrigin = h.checkOrigin
+	return h
+}
+
+// WithAllowedOrigins lets pages from other origins than the server's own,
+// e.g. "https://app.example.com", open live sessions
+func (h *LiveHandler) WithAllowedOrigins(origins []string) *LiveHandler {
+	h.allowedOrigins = origins
+	return h
+}
+
+// checkOrigin keeps pages of other sites from editing documents with the
+// credentials a browser holds for this server. Clients that are not
+// browsers send no Origin.
+func (h *LiveHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return slices.Contains(h.allowedOrigins, origin)
+}
+
+// HandleLive joins the live session of a document and relays operations
+// between the connection and the session
+func (h *LiveHandler) HandleLive(w http.ResponseWriter, r *http.Request) {
+	// Add security headers
+	h.addSecurityHeaders(w)
+
+	if !h.checkOrigin(r) {
+		http.Error(w, "Origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	client, err := h.liveUsecase.Join(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeJoinError(w, err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Error upgrading connection: %v", err)
+		h.leave(client)
+		return
+	}
+	conn.SetReadLimit(maxLiveMessageSize)
+
+	// Messages from the session are written until it lets the client go
+	go func() {
+		defer conn.Close()
+		for message := range client.Messages() {
+			if err := conn.WriteJSON(message); err != nil {
+				return
+			}
+		}
+		closeConn(conn, websocket.CloseNormalClosure, "")
+	}()
+	defer h.leave(client)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req liveRequest
+		if err := json.Unmarshal(data, &req); err != nil || req.Type != usecase.LiveOps {
+			closeConn(conn, websocket.CloseInvalidFramePayloadData, "expected an ops message")
+			return
+		}
+		if err := h.liveUsecase.Apply(r.Context(), client, req.Ops); errors.Is(err, entity.ErrLiveSessionClosed) {
+			return
+		}
+	}
+}
+
+// Persist stores the live sessions with pending changes and announces the
+// documents stored
+func (h *LiveHandler) Persist(ctx context.Context) error {
+	saves, err := h.liveUsecase.Persist(ctx)
+	for _, save := range saves {
+		h.notify(save)
+	}
+	return err
+}
+
+// leave takes a client out of its session, announcing the document if its
+// last changes were stored on the way
+func (h *LiveHandler) leave(client *usecase.LiveClient) {
+	save, err := h.liveUsecase.Leave(usecase.Internal(context.Background()), client)
+	if err != nil {
+		log.Printf("Error storing live session: %v", err)
+		return
+	}
+	if save != nil {
+		h.notify(save)
+	}
+}
+
+// notify broadcasts a document stored from a live session
+func (h *LiveHandler) notify(save *usecase.LiveSave) {
+	if h.notifier == nil {
+		return
+	}
+	h.notifier.BroadcastNotification(entity.NewNotification(
+		save.Editor.ID,
+		save.Editor.Name,
+		save.Document.ID,
+		save.Document.Title,
+		"document.updated",
+	))
+}
+
+// closeConn sends a close frame. Unlike data messages it may be sent while
+// another goroutine writes to the connection.
+func closeConn(conn *websocket.Conn, code int, text string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+}
+
+// writeJoinError answers a handshake for a session that cannot be joined
+func writeJoinError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, entity.ErrDocumentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, entity.ErrAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, entity.ErrUnauthenticated):
+		http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// addSecurityHeaders adds security headers
+func (h *LiveHandler) addSecurityHeaders(w http.ResponseWriter) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("X-XSS-Protection", "1; mode=block")
+	w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+}
@@ -51,7 +51,8 @@ func (h *NotificationHandler) HandleNotifications(w http.ResponseWriter, r *http
 		return
 	}
 
-	h.hub.Register(conn)
+	// The request validator only lets authenticated handshakes through
+	h.hub.Register(conn, r.Header.Get("user-id"))
 	defer func() {
 		h.hub.Unregister(conn)
 		conn.Close()
@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Permissions on a document; each one includes those before it
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionAdmin = "admin"
+)
+
+// permissionRank orders permissions, none being 0
+var permissionRank = map[string]int{
+	PermissionRead:  1,
+	PermissionWrite: 2,
+	PermissionAdmin: 3,
+}
+
+// rolePermissions maps contributor roles to the permission they grant
+var rolePermissions = map[string]string{
+	RoleOwner:  PermissionAdmin,
+	RoleEditor: PermissionWrite,
+	RoleViewer: PermissionRead,
+}
+
+// Grant gives a user or a group a permission on a document
+type Grant struct {
+	UserID     string `json:"userId,omitempty"`
+	Group      string `json:"group,omitempty"`
+	Permission string `json:"permission"`
+}
+
+// Validate checks that the grant names exactly one user or group and a
+// known permission
+func (g Grant) Validate() error {
+	if (g.UserID == "") == (g.Group == "") {
+		return fmt.Errorf("%w: a grant needs either a userId or a group", ErrInvalidGrant)
+	}
+	if permissionRank[g.Permission] == 0 {
+		return fmt.Errorf("%w: permission must be read, write or admin", ErrInvalidGrant)
+	}
+	return nil
+}
+
+// Principal is a user together with the groups it belongs to
+type Principal struct {
+	UserID string
+	Groups []string
+}
+
+// IsPublic reports whether the document has no owner. Documents created
+// before ownership existed can be read and written by everybody.
+func (d *Document) IsPublic() bool {
+	return d.OwnerID == ""
+}
+
+// PermissionOf returns the highest permission the principal holds on the
+// document through ownership, contributor roles and grants, or "" if none.
+// Everybody may write a public document, but only its contributors and
+// grants give admin on it.
+func (d *Document) PermissionOf(p Principal) string {
+	if !d.IsPublic() && d.OwnerID == p.UserID {
+		return PermissionAdmin
+	}
+	best := ""
+	if d.IsPublic() {
+		best = PermissionWrite
+	}
+	raise := func(permission string) {
+		if permissionRank[permission] > permissionRank[best] {
+			best = permission
+		}
+	}
+	if contributor, ok := d.Contributor(p.UserID); ok {
+		raise(rolePermissions[contributor.EffectiveRole()])
+	}
+	for _, grant := range d.ACL {
+		if grant.UserID != "" && grant.UserID == p.UserID || grant.Group != "" && slices.Contains(p.Groups, grant.Group) {
+			raise(grant.Permission)
+		}
+	}
+	return best
+}
+
+// Allows reports whether the principal holds at least the permission
+func (d *Document) Allows(p Principal, permission string) bool {
+	return permissionRank[d.PermissionOf(p)] >= permissionRank[permission]
+}
+
+// validateACL checks the grants of a document
+func (d *Document) validateACL() error {
+	type subject struct{ user, group string }
+	seen := make(map[subject]bool, len(d.ACL))
+	for _, grant := range d.ACL {
+		if err := grant.Validate(); err != nil {
+			return err
+		}
+		key := subject{grant.UserID, grant.Group}
+		if seen[key] {
+			return fmt.Errorf("%w: %s has more than one grant", ErrInvalidGrant, grant.UserID+grant.Group)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// AccessDeniedError reports a user lacking a permission on a document
+type AccessDeniedError struct {
+	UserID     string
+	DocumentID string
+	Permission string
+}
+
+// Error implements error
+func (e *AccessDeniedError) Error() string {
+	return fmt.Sprintf("%v: %s permission required on document %s", ErrAccessDenied, e.Permission, e.DocumentID)
+}
+
+// Unwrap makes errors.Is match ErrAccessDenied
+func (e *AccessDeniedError) Unwrap() error {
+	return ErrAccessDenied
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// maxAttachmentNameLen is the longest attachment name in bytes
+const maxAttachmentNameLen = 255
+
+// Attachment is a file attached to a document. Attachments created before
+// uploads existed only carry a name.
+type Attachment struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	// Checksum is the hex SHA-256 of the content, which also addresses it
+	// in the blob store
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// NewAttachment creates an attachment that only has a name
+func NewAttachment(name string) Attachment {
+	return Attachment{Name: name}
+}
+
+// HasContent reports whether the attachment was uploaded, as opposed to a
+// bare name
+func (a Attachment) HasContent() bool {
+	return a.Checksum != ""
+}
+
+// ValidateAttachmentName checks that a name can be used for an attachment
+// and in a Content-Disposition header
+func ValidateAttachmentName(name string) error {
+	if name == "" || name == "." || name == ".." || len(name) > maxAttachmentNameLen {
+		return ErrInvalidAttachment
+	}
+	if strings.ContainsAny(name, `/\"`) || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return ErrInvalidAttachment
+	}
+	return nil
+}
+
+// UnmarshalJSON accepts the attachment object as well as the legacy form,
+// a plain string holding the name
+func (a *Attachment) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*a = NewAttachment(name)
+		return nil
+	}
+
+	// The alias drops this method so the object decodes normally
+	type attachment Attachment
+	var decoded attachment
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*a = Attachment(decoded)
+	return nil
+}
@@ -0,0 +1,37 @@
+package entity
+
+// Contributor roles on a document
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Contributor is a user taking part in a document with a role. Contributors
+// stored before roles existed have none and count as editors.
+type Contributor struct {
+	User
+	Role string `json:"role,omitempty"`
+}
+
+// NewContributor creates a contributor with the given role
+func NewContributor(user User, role string) Contributor {
+	return Contributor{User: user, Role: role}
+}
+
+// EffectiveRole returns the role of the contributor, defaulting to editor
+func (c Contributor) EffectiveRole() string {
+	if c.Role == "" {
+		return RoleEditor
+	}
+	return c.Role
+}
+
+// ValidateRole checks that role is a known contributor role
+func ValidateRole(role string) error {
+	switch role {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return nil
+	}
+	return ErrInvalidContributorRole
+}
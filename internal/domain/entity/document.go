@@ -1,16 +1,32 @@
 package entity
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"time"
+
+	"frontend-challenge/pkg/semver"
+)
 
 // Document represents a document in the domain
 type Document struct {
-	ID           string    `json:"id"`
-	Title        string    `json:"title"`
-	Version      string    `json:"version"`
-	Attachments  []string  `json:"attachments"`
-	Contributors []User    `json:"contributors"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID           string        `json:"id"`
+	Title        string        `json:"title"`
+	Version      string        `json:"version"`
+	Attachments  []Attachment  `json:"attachments"`
+	Contributors []Contributor `json:"contributors"`
+	// Tags are normalized, sorted and unique
+	Tags []string `json:"tags,omitempty"`
+	// OwnerID is the user who created the document; documents without an
+	// owner are public
+	OwnerID string `json:"ownerId,omitempty"`
+	// ACL grants users and groups access besides owner and contributors
+	ACL       []Grant    `json:"acl,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
 
 // NewDocument creates a new instance of Document
@@ -25,18 +41,83 @@ func NewDocument(id, title, version string) *Document {
 	}
 }
 
-// AddContributor adds a contributor to the document
-func (d *Document) AddContributor(user User) {
-	d.Contributors = append(d.Contributors, user)
+// AddContributor adds a user to the document with the given role
+func (d *Document) AddContributor(user User, role string) error {
+	if err := ValidateRole(role); err != nil {
+		return err
+	}
+	if _, exists := d.Contributor(user.ID); exists {
+		return ErrContributorExists
+	}
+	d.Contributors = append(d.Contributors, NewContributor(user, role))
 	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveContributor removes a user from the document
+func (d *Document) RemoveContributor(userID string) (Contributor, error) {
+	for i, contributor := range d.Contributors {
+		if contributor.ID == userID {
+			d.Contributors = append(d.Contributors[:i:i], d.Contributors[i+1:]...)
+			return contributor, nil
+		}
+	}
+	return Contributor{}, ErrContributorNotFound
+}
+
+// Contributor returns the contributor with the given user ID
+func (d *Document) Contributor(userID string) (Contributor, bool) {
+	for _, contributor := range d.Contributors {
+		if contributor.ID == userID {
+			return contributor, true
+		}
+	}
+	return Contributor{}, false
 }
 
 // AddAttachment adds an attachment to the document
-func (d *Document) AddAttachment(attachment string) {
+func (d *Document) AddAttachment(attachment Attachment) {
 	d.Attachments = append(d.Attachments, attachment)
 	d.UpdatedAt = time.Now()
 }
 
+// PutAttachment adds an attachment, replacing any attachment with the
+// same name
+func (d *Document) PutAttachment(attachment Attachment) {
+	for i, existing := range d.Attachments {
+		if existing.Name == attachment.Name {
+			d.Attachments[i] = attachment
+			return
+		}
+	}
+	d.Attachments = append(d.Attachments, attachment)
+}
+
+// Attachment returns the attachment with the given name
+func (d *Document) Attachment(name string) (Attachment, bool) {
+	for _, attachment := range d.Attachments {
+		if attachment.Name == name {
+			return attachment, true
+		}
+	}
+	return Attachment{}, false
+}
+
+// MarkDeleted moves the document to the trash
+func (d *Document) MarkDeleted(now time.Time) {
+	d.DeletedAt = &now
+}
+
+// Restore takes the document out of the trash
+func (d *Document) Restore() {
+	d.DeletedAt = nil
+}
+
+// IsDeleted reports whether the document is in the trash
+func (d *Document) IsDeleted() bool {
+	return d.DeletedAt != nil
+}
+
 // Validate validates the document's data
 func (d *Document) Validate() error {
 	if d.ID == "" {
@@ -45,8 +126,52 @@ func (d *Document) Validate() error {
 	if d.Title == "" {
 		return ErrInvalidDocumentTitle
 	}
-	if d.Version == "" {
+	if _, err := semver.Parse(d.Version); err != nil {
 		return ErrInvalidDocumentVersion
 	}
-	return nil
+	for _, attachment := range d.Attachments {
+		if err := ValidateAttachmentName(attachment.Name); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]bool, len(d.Contributors))
+	for _, contributor := range d.Contributors {
+		if contributor.Role != "" {
+			if err := ValidateRole(contributor.Role); err != nil {
+				return err
+			}
+		}
+		if seen[contributor.ID] {
+			return ErrContributorExists
+		}
+		seen[contributor.ID] = true
+	}
+	for _, tag := range d.Tags {
+		if _, err := NormalizeTag(tag); err != nil {
+			return err
+		}
+	}
+	return d.validateACL()
+}
+
+// Clone returns a deep copy of the document
+func (d *Document) Clone() *Document {
+	clone := *d
+	// slices.Clone keeps empty slices empty rather than nil, so they still
+	// encode as []
+	clone.Attachments = slices.Clone(d.Attachments)
+	clone.Contributors = slices.Clone(d.Contributors)
+	clone.Tags = slices.Clone(d.Tags)
+	clone.ACL = slices.Clone(d.ACL)
+	if d.DeletedAt != nil {
+		deletedAt := *d.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	return &clone
+}
+
+// ETag returns a strong entity tag that changes on every update
+func (d *Document) ETag() string {
+	sum := sha256.Sum256([]byte(d.ID + ":" + strconv.FormatInt(d.UpdatedAt.UnixNano(), 10)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
 }
@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DocumentJSON is the JSON form of a document. attachments keeps the form
+// clients have always read, the list of attachment names, while files
+// describes each attachment with its uploaded content. Clients may send
+// attachments as names or objects, files, or both: attachments then says
+// which attachments the document has and files fills in their content.
+type DocumentJSON struct {
+	ID           string          `json:"id"`
+	Title        string          `json:"title"`
+	Version      string          `json:"version"`
+	Attachments  AttachmentNames `json:"attachments"`
+	Files        []Attachment    `json:"files,omitempty"`
+	Contributors []Contributor   `json:"contributors"`
+	Tags         []string        `json:"tags,omitempty"`
+	OwnerID      string          `json:"ownerId,omitempty"`
+	ACL          []Grant         `json:"acl,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	UpdatedAt    time.Time       `json:"updatedAt"`
+	DeletedAt    *time.Time      `json:"deletedAt,omitempty"`
+}
+
+// AttachmentNames is written as the names of the attachments and read in
+// either form
+type AttachmentNames []Attachment
+
+// MarshalJSON writes the attachment names
+func (a AttachmentNames) MarshalJSON() ([]byte, error) {
+	if a == nil {
+		return []byte("null"), nil
+	}
+	names := make([]string, len(a))
+	for i, attachment := range a {
+		names[i] = attachment.Name
+	}
+	return json.Marshal(names)
+}
+
+// JSON returns the JSON form of the document
+func (d *Document) JSON() DocumentJSON {
+	return DocumentJSON{
+		ID:           d.ID,
+		Title:        d.Title,
+		Version:      d.Version,
+		Attachments:  AttachmentNames(d.Attachments),
+		Files:        d.Attachments,
+		Contributors: d.Contributors,
+		Tags:         d.Tags,
+		OwnerID:      d.OwnerID,
+		ACL:          d.ACL,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
+		DeletedAt:    d.DeletedAt,
+	}
+}
+
+// Document returns the document described by its JSON form
+func (j DocumentJSON) Document() *Document {
+	return &Document{
+		ID:           j.ID,
+		Title:        j.Title,
+		Version:      j.Version,
+		Attachments:  MergeAttachments(j.Attachments, j.Files),
+		Contributors: j.Contributors,
+		Tags:         j.Tags,
+		OwnerID:      j.OwnerID,
+		ACL:          j.ACL,
+		CreatedAt:    j.CreatedAt,
+		UpdatedAt:    j.UpdatedAt,
+		DeletedAt:    j.DeletedAt,
+	}
+}
+
+// MergeAttachments combines the two attachment lists of the JSON form.
+// attachments decides which attachments there are, in which order; those
+// given by name only take the content of the file with the same name.
+// Without attachments, files is used as it is.
+func MergeAttachments(attachments, files []Attachment) []Attachment {
+	if attachments == nil {
+		return files
+	}
+	byName := make(map[string]Attachment, len(files))
+	for _, file := range files {
+		byName[file.Name] = file
+	}
+	merged := make([]Attachment, len(attachments))
+	for i, attachment := range attachments {
+		if file, ok := byName[attachment.Name]; ok && !attachment.HasContent() {
+			attachment = file
+		}
+		merged[i] = attachment
+	}
+	return merged
+}
+
+// MarshalJSON writes the JSON form of the document
+func (d Document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.JSON())
+}
+
+// UnmarshalJSON reads the JSON form of a document
+func (d *Document) UnmarshalJSON(data []byte) error {
+	var j DocumentJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*d = *j.Document()
+	return nil
+}
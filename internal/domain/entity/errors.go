@@ -13,4 +13,41 @@ var (
 	ErrUserNotFound            = errors.New("user not found")
 	ErrDocumentNotFound        = errors.New("document not found")
 	ErrInvalidNotification     = errors.New("invalid notification")
+	ErrDocumentVersionConflict = errors.New("document was modified by another request")
+	ErrPreconditionFailed      = errors.New("document does not match the expected entity tag")
+	ErrDocumentNotDeleted      = errors.New("document is not in the trash")
+	ErrInvalidCursor           = errors.New("invalid pagination cursor")
+	ErrInvalidPageLimit        = errors.New("invalid page limit")
+	ErrInvalidSearchQuery      = errors.New("search query must contain at least one word")
+	ErrDocumentAlreadyExists   = errors.New("document already exists")
+	ErrInvalidBatchOperation   = errors.New("invalid batch operation")
+	ErrBatchAborted            = errors.New("batch aborted")
+	ErrRevisionNotFound        = errors.New("revision not found")
+	ErrInvalidRevision         = errors.New("invalid revision number")
+	ErrVersionRegression       = errors.New("document version cannot move backwards")
+	ErrInvalidVersionBump      = errors.New("bump must be major, minor or patch")
+	ErrInvalidAttachment       = errors.New("invalid attachment")
+	ErrAttachmentTooLarge      = errors.New("attachment too large")
+	ErrAttachmentNotUploaded   = errors.New("attachment content must be uploaded")
+	ErrAttachmentNotFound      = errors.New("attachment not found")
+	ErrInvalidContributorRole  = errors.New("contributor role must be owner, editor or viewer")
+	ErrContributorExists       = errors.New("user is already a contributor")
+	ErrContributorNotFound     = errors.New("contributor not found")
+	ErrInvalidImport           = errors.New("invalid import")
+	ErrUploadNotFound          = errors.New("upload not found")
+	ErrUploadExpired           = errors.New("upload expired")
+	ErrUploadOffsetMismatch    = errors.New("upload offset does not match")
+	ErrUploadBusy              = errors.New("upload is being written by another request")
+	ErrTemplateNotFound        = errors.New("template not found")
+	ErrInvalidTemplate         = errors.New("invalid template")
+	ErrTemplateAlreadyExists   = errors.New("template already exists")
+	ErrInvalidTag              = errors.New("tags may only contain letters, digits, spaces and - _ . ! ?")
+	ErrTagNotFound             = errors.New("tag not found")
+	ErrAccessDenied            = errors.New("access denied")
+	ErrUnauthenticated         = errors.New("authentication required")
+	ErrInvalidGrant            = errors.New("invalid grant")
+	ErrDocumentLocked          = errors.New("document is locked")
+	ErrLockNotFound            = errors.New("document is not locked")
+	ErrLiveSessionClosed       = errors.New("live session closed")
+	ErrRollbackFailed          = errors.New("write could not be undone")
 )
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lock is an exclusive edit lease on a document. Only its holder may change
+// the document until the lock is released or expires.
+type Lock struct {
+	DocumentID string    `json:"documentId"`
+	UserID     string    `json:"userId"`
+	UserName   string    `json:"userName"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Expired reports whether the lease has run out at the given time
+func (l *Lock) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// Clone returns a copy of the lock
+func (l *Lock) Clone() *Lock {
+	clone := *l
+	return &clone
+}
+
+// LockedError reports a change refused because another user holds the
+// document's lock
+type LockedError struct {
+	Lock *Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s: document %s is being edited by %s until %s",
+		ErrDocumentLocked, e.Lock.DocumentID, e.Lock.UserName, e.Lock.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrDocumentLocked
+}
@@ -10,6 +10,13 @@ type Notification struct {
 	DocumentID    string    `json:"documentId"`
 	DocumentTitle string    `json:"documentTitle"`
 	Type          string    `json:"type"`
+	// DocumentIDs lists every affected document of a notification that
+	// covers several documents at once
+	DocumentIDs []string `json:"documentIds,omitempty"`
+	// RecipientID restricts delivery to a single user
+	RecipientID string `json:"recipientId,omitempty"`
+	// Lock is the lock a document.locked or document.unlocked event is about
+	Lock *Lock `json:"lock,omitempty"`
 }
 
 // NewNotification creates a new instance of Notification
@@ -0,0 +1,166 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Revision actions
+const (
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+	RevisionReverted = "reverted"
+	RevisionReleased = "released"
+)
+
+// Revision is an immutable snapshot of a document after a change
+type Revision struct {
+	DocumentID string    `json:"documentId"`
+	Number     int       `json:"number"`
+	Action     string    `json:"action"`
+	AuthorID   string    `json:"authorId"`
+	AuthorName string    `json:"authorName"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Snapshot   Document  `json:"snapshot"`
+}
+
+// NewRevision creates a revision holding a copy of the document.
+// The repository assigns the revision number.
+func NewRevision(document *Document, action string, author User) *Revision {
+	return &Revision{
+		DocumentID: document.ID,
+		Action:     action,
+		AuthorID:   author.ID,
+		AuthorName: author.Name,
+		CreatedAt:  time.Now(),
+		Snapshot:   *document.Clone(),
+	}
+}
+
+// FieldChange records the old and new value of a changed field
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// AttachmentsChange lists the attachments added and removed
+type AttachmentsChange struct {
+	Added   []Attachment `json:"added"`
+	Removed []Attachment `json:"removed"`
+}
+
+// ContributorsChange lists the contributors added and removed
+type ContributorsChange struct {
+	Added   []Contributor `json:"added"`
+	Removed []Contributor `json:"removed"`
+}
+
+// TagsChange lists the tags added and removed
+type TagsChange struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// DocumentDiff is the structural difference between two versions of a
+// document. Fields that did not change are nil.
+type DocumentDiff struct {
+	Title        *FieldChange        `json:"title,omitempty"`
+	Version      *FieldChange        `json:"version,omitempty"`
+	Attachments  *AttachmentsChange  `json:"attachments,omitempty"`
+	Contributors *ContributorsChange `json:"contributors,omitempty"`
+	Tags         *TagsChange         `json:"tags,omitempty"`
+}
+
+// DiffDocuments compares two versions of a document
+func DiffDocuments(from, to *Document) DocumentDiff {
+	var diff DocumentDiff
+	if from.Title != to.Title {
+		diff.Title = &FieldChange{From: from.Title, To: to.Title}
+	}
+	if from.Version != to.Version {
+		diff.Version = &FieldChange{From: from.Version, To: to.Version}
+	}
+
+	added, removed := diffAttachments(from.Attachments, to.Attachments)
+	if len(added) > 0 || len(removed) > 0 {
+		diff.Attachments = &AttachmentsChange{Added: added, Removed: removed}
+	}
+
+	addedUsers, removedUsers := diffContributors(from.Contributors, to.Contributors)
+	if len(addedUsers) > 0 || len(removedUsers) > 0 {
+		diff.Contributors = &ContributorsChange{Added: addedUsers, Removed: removedUsers}
+	}
+
+	addedTags, removedTags := diffTags(from.Tags, to.Tags)
+	if len(addedTags) > 0 || len(removedTags) > 0 {
+		diff.Tags = &TagsChange{Added: addedTags, Removed: removedTags}
+	}
+	return diff
+}
+
+// diffAttachments compares two lists as multisets. Replacing the content
+// of an attachment shows up as a removal and an addition.
+func diffAttachments(from, to []Attachment) (added, removed []Attachment) {
+	counts := make(map[Attachment]int)
+	for _, a := range from {
+		counts[a]++
+	}
+	added, removed = []Attachment{}, []Attachment{}
+	for _, a := range to {
+		if counts[a] > 0 {
+			counts[a]--
+		} else {
+			added = append(added, a)
+		}
+	}
+	for _, a := range from {
+		if counts[a] > 0 {
+			counts[a]--
+			removed = append(removed, a)
+		}
+	}
+	return added, removed
+}
+
+// diffContributors compares two lists of contributors by user ID and
+// role. A role change shows up as a removal and an addition.
+func diffContributors(from, to []Contributor) (added, removed []Contributor) {
+	before := make(map[string]string, len(from))
+	for _, c := range from {
+		before[c.ID] = c.EffectiveRole()
+	}
+	after := make(map[string]string, len(to))
+	for _, c := range to {
+		after[c.ID] = c.EffectiveRole()
+	}
+	added, removed = []Contributor{}, []Contributor{}
+	for _, c := range to {
+		if role, ok := before[c.ID]; !ok || role != c.EffectiveRole() {
+			added = append(added, c)
+		}
+	}
+	for _, c := range from {
+		if role, ok := after[c.ID]; !ok || role != c.EffectiveRole() {
+			removed = append(removed, c)
+		}
+	}
+	return added, removed
+}
+
+// diffTags compares two tag sets
+func diffTags(from, to []string) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for _, tag := range to {
+		if !slices.Contains(from, tag) {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range from {
+		if !slices.Contains(to, tag) {
+			removed = append(removed, tag)
+		}
+	}
+	return added, removed
+}
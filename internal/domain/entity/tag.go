@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"slices"
+	"strings"
+
+	"frontend-challenge/pkg/security"
+)
+
+// maxTagLen is the longest tag in bytes
+const maxTagLen = 50
+
+// tagSanitizer restricts tags to plain text
+var tagSanitizer = security.NewSanitizer()
+
+// NormalizeTag returns the canonical form of a tag: lower case, with runs
+// of whitespace turned into a single dash. Tags must pass
+// Sanitizer.SanitizeAlphanumeric and cannot contain commas, which separate
+// tags in queries.
+func NormalizeTag(tag string) (string, error) {
+	sanitized, err := tagSanitizer.SanitizeAlphanumeric(tag)
+	if err != nil {
+		return "", ErrInvalidTag
+	}
+	normalized := strings.ToLower(strings.Join(strings.Fields(sanitized), "-"))
+	if len(normalized) > maxTagLen || strings.Contains(normalized, ",") {
+		return "", ErrInvalidTag
+	}
+	return normalized, nil
+}
+
+// NormalizeTags normalizes every tag and returns them sorted, without
+// duplicates
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		t, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, t)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// AddTags adds tags to the document, ignoring those it already has
+func (d *Document) AddTags(tags ...string) error {
+	merged, err := NormalizeTags(append(slices.Clone(d.Tags), tags...))
+	if err != nil {
+		return err
+	}
+	d.Tags = merged
+	return nil
+}
+
+// RemoveTag removes a tag from the document
+func (d *Document) RemoveTag(tag string) error {
+	normalized, err := NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	i := slices.Index(d.Tags, normalized)
+	if i < 0 {
+		return ErrTagNotFound
+	}
+	d.Tags = slices.Delete(slices.Clone(d.Tags), i, i+1)
+	if len(d.Tags) == 0 {
+		d.Tags = nil
+	}
+	return nil
+}
+
+// HasTag reports whether the document carries a normalized tag
+func (d *Document) HasTag(tag string) bool {
+	return slices.Contains(d.Tags, tag)
+}
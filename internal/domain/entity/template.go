@@ -0,0 +1,150 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"frontend-challenge/pkg/semver"
+)
+
+// placeholderPattern matches a {{name}} placeholder in a template
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z.]*)\s*\}\}`)
+
+// Template placeholders
+const (
+	PlaceholderDate       = "date"
+	PlaceholderTime       = "time"
+	PlaceholderDateTime   = "datetime"
+	PlaceholderUserID     = "user.id"
+	PlaceholderUserName   = "user.name"
+	PlaceholderDocumentID = "document.id"
+)
+
+// Template is a blueprint for new documents
+type Template struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// TitlePattern becomes the title of the document once its placeholders
+	// are replaced; attachment names may hold placeholders too
+	TitlePattern string        `json:"titlePattern"`
+	Version      string        `json:"version"`
+	Attachments  []Attachment  `json:"attachments"`
+	Contributors []Contributor `json:"contributors"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+}
+
+// TemplateValues are what placeholders are replaced with
+type TemplateValues struct {
+	DocumentID string
+	User       User
+	Now        time.Time
+}
+
+// Validate checks the template and the placeholders it uses
+func (t *Template) Validate() error {
+	if t.ID == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalidTemplate)
+	}
+	if t.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	}
+	if t.TitlePattern == "" {
+		return fmt.Errorf("%w: titlePattern is required", ErrInvalidTemplate)
+	}
+	if err := checkPlaceholders(t.TitlePattern); err != nil {
+		return err
+	}
+	if _, err := semver.Parse(t.Version); err != nil {
+		return ErrInvalidDocumentVersion
+	}
+	for _, attachment := range t.Attachments {
+		if attachment.HasContent() {
+			return fmt.Errorf("%w: attachment %q carries content", ErrInvalidTemplate, attachment.Name)
+		}
+		if err := ValidateAttachmentName(attachment.Name); err != nil {
+			return err
+		}
+		if err := checkPlaceholders(attachment.Name); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]bool, len(t.Contributors))
+	for _, contributor := range t.Contributors {
+		if contributor.ID == "" {
+			return ErrInvalidUserID
+		}
+		if contributor.Role != "" {
+			if err := ValidateRole(contributor.Role); err != nil {
+				return err
+			}
+		}
+		if seen[contributor.ID] {
+			return ErrContributorExists
+		}
+		seen[contributor.ID] = true
+	}
+	return nil
+}
+
+// Instantiate creates a document from the template, replacing the
+// placeholders with the given values
+func (t *Template) Instantiate(values TemplateValues) *Document {
+	document := NewDocument(values.DocumentID, expandPlaceholders(t.TitlePattern, values), t.Version)
+	for _, attachment := range t.Attachments {
+		attachment.Name = expandPlaceholders(attachment.Name, values)
+		document.AddAttachment(attachment)
+	}
+	for _, contributor := range t.Contributors {
+		contributor.CreatedAt = values.Now
+		contributor.UpdatedAt = values.Now
+		document.Contributors = append(document.Contributors, contributor)
+	}
+	document.CreatedAt = values.Now
+	document.UpdatedAt = values.Now
+	return document
+}
+
+// Clone returns a deep copy of the template
+func (t *Template) Clone() *Template {
+	clone := *t
+	clone.Attachments = slices.Clone(t.Attachments)
+	clone.Contributors = slices.Clone(t.Contributors)
+	return &clone
+}
+
+// checkPlaceholders rejects placeholders that would not be replaced
+func checkPlaceholders(pattern string) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(pattern, -1) {
+		switch match[1] {
+		case PlaceholderDate, PlaceholderTime, PlaceholderDateTime,
+			PlaceholderUserID, PlaceholderUserName, PlaceholderDocumentID:
+		default:
+			return fmt.Errorf("%w: unknown placeholder %s", ErrInvalidTemplate, match[0])
+		}
+	}
+	return nil
+}
+
+// expandPlaceholders replaces the placeholders of a pattern
+func expandPlaceholders(pattern string, values TemplateValues) string {
+	return placeholderPattern.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		switch placeholderPattern.FindStringSubmatch(placeholder)[1] {
+		case PlaceholderDate:
+			return values.Now.Format(time.DateOnly)
+		case PlaceholderTime:
+			return values.Now.Format("15:04")
+		case PlaceholderDateTime:
+			return values.Now.Format(time.RFC3339)
+		case PlaceholderUserID:
+			return values.User.ID
+		case PlaceholderUserName:
+			return values.User.Name
+		case PlaceholderDocumentID:
+			return values.DocumentID
+		}
+		return placeholder
+	})
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+// UploadSession is an attachment upload sent in several requests. The
+// content received so far is staged until Offset reaches Length.
+type UploadSession struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"documentId"`
+	Name       string    `json:"name"`
+	MimeType   string    `json:"mimeType,omitempty"`
+	Length     int64     `json:"length"`
+	Offset     int64     `json:"offset"`
+	AuthorID   string    `json:"authorId"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// NewUploadSession creates an empty upload session that expires after ttl
+func NewUploadSession(id, documentID, name, mimeType string, length int64, author User, ttl time.Duration) *UploadSession {
+	now := time.Now()
+	return &UploadSession{
+		ID:         id,
+		DocumentID: documentID,
+		Name:       name,
+		MimeType:   mimeType,
+		Length:     length,
+		AuthorID:   author.ID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+}
+
+// IsStartedBy reports whether user started the upload
+func (s *UploadSession) IsStartedBy(user User) bool {
+	return s.AuthorID == user.ID
+}
+
+// IsComplete reports whether all the content was received
+func (s *UploadSession) IsComplete() bool {
+	return s.Offset == s.Length
+}
+
+// IsExpired reports whether the session can no longer be resumed
+func (s *UploadSession) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
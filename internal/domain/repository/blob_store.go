@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Blob describes stored content
+type Blob struct {
+	// Checksum is the hex SHA-256 of the content and its address in the store
+	Checksum string
+	Size     int64
+}
+
+// BlobStore defines the interface for content-addressed binary storage.
+// Storing the same content twice keeps a single copy. Content is never
+// deleted one checksum at a time, as it may be shared: Sweep reclaims what
+// nothing refers to any more.
+type BlobStore interface {
+	// Put stores the content read from r, failing with
+	// entity.ErrAttachmentTooLarge once more than maxSize bytes are read.
+	// Putting content that is already stored counts as storing it again for
+	// Sweep.
+	Put(ctx context.Context, r io.Reader, maxSize int64) (*Blob, error)
+
+	// Open returns the content with the given checksum
+	Open(ctx context.Context, checksum string) (io.ReadSeekCloser, error)
+
+	// Sweep deletes the content last stored before cutoff for which inUse
+	// reports false, and returns how many were deleted
+	Sweep(ctx context.Context, cutoff time.Time, inUse func(checksum string) bool) (int, error)
+}
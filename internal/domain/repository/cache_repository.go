@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"frontend-challenge/internal/domain/entity"
 )
@@ -17,6 +18,11 @@ type CacheRepository interface {
 	// GetAll retrieves all documents from the cache
 	GetAll(ctx context.Context) ([]*entity.Document, error)
 
+	// Range calls fn for every document in key order until fn returns
+	// false. Documents are looked up one at a time, so the cache is not
+	// locked while fn runs and changes made meanwhile may or may not be seen.
+	Range(ctx context.Context, fn func(key string, document *entity.Document) bool) error
+
 	// Delete removes a document from the cache
 	Delete(ctx context.Context, key string) error
 
@@ -29,6 +35,10 @@ type CacheRepository interface {
 	// Count returns the number of documents in the cache
 	Count(ctx context.Context) int
 
+	// LastExpiry returns when the last document to expire did so, the zero
+	// time if none has
+	LastExpiry(ctx context.Context) time.Time
+
 	// GetStats returns cache statistics
 	GetStats() map[string]interface{}
 }
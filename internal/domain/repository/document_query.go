@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// DocumentFilter selects documents. Zero-valued fields match everything.
+type DocumentFilter struct {
+	// Title matches the title exactly, ignoring case
+	Title string
+	// TitleContains matches titles containing the text, ignoring case
+	TitleContains string
+	// Version matches the version exactly
+	Version string
+	// ContributorID matches documents the user contributed to
+	ContributorID string
+	// Tags matches documents carrying all of the normalized tags, or any
+	// of them with TagMode TagMatchAny
+	Tags    []string
+	TagMode TagMode
+	// ReadableBy matches documents the principal may read
+	ReadableBy    *entity.Principal
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+// TagMode decides how a filter on several tags matches
+type TagMode string
+
+// Tag matching modes
+const (
+	TagMatchAll TagMode = "all"
+	TagMatchAny TagMode = "any"
+)
+
+// TagCount is a tag and the number of documents carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// SortField is a document field listings can be ordered by
+type SortField string
+
+// Sortable document fields
+const (
+	SortByCreatedAt SortField = "createdAt"
+	SortByUpdatedAt SortField = "updatedAt"
+	SortByTitle     SortField = "title"
+	SortByVersion   SortField = "version"
+)
+
+// SortFields lists the fields listings can be ordered by
+var SortFields = []SortField{SortByCreatedAt, SortByUpdatedAt, SortByTitle, SortByVersion}
+
+// SortOrder orders a listing by one field
+type SortOrder struct {
+	Field      SortField
+	Descending bool
+}
+
+// FormatSort renders a sort specification as "-updatedAt,title"
+func FormatSort(order []SortOrder) string {
+	parts := make([]string, len(order))
+	for i, o := range order {
+		parts[i] = string(o.Field)
+		if o.Descending {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// DocumentQuery selects, orders and pages documents outside the trash
+type DocumentQuery struct {
+	Filter DocumentFilter
+	// Sort defaults to creation time when empty
+	Sort []SortOrder
+	Page PageRequest
+}
+
+// SearchHit is a document matching a full-text search
+type SearchHit struct {
+	Document *entity.Document
+	// Score is the relevance of the document, higher is better
+	Score float64
+	// Highlights holds the matching values per field with the matched
+	// words wrapped in <mark> tags
+	Highlights map[string][]string
+}
@@ -2,24 +2,62 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"frontend-challenge/internal/domain/entity"
 )
 
 // DocumentRepository defines the interface for the document repository
 type DocumentRepository interface {
-	// GetAll retrieves all documents
+	// GetAll retrieves all documents that are not in the trash
 	GetAll(ctx context.Context) ([]*entity.Document, error)
 
-	// GetByID retrieves a document by its ID
+	// Query retrieves one page of the documents outside the trash that
+	// match the filter, in the requested order
+	Query(ctx context.Context, query DocumentQuery) (*DocumentPage, error)
+
+	// Search ranks the documents outside the trash by relevance to a
+	// free-text query over titles, attachments and contributor names
+	Search(ctx context.Context, query string, limit int) ([]SearchHit, error)
+
+	// TagCounts returns how often each tag is used by the documents
+	// outside the trash that match the filter, most used first
+	TagCounts(ctx context.Context, filter DocumentFilter) ([]TagCount, error)
+
+	// Iterate calls fn for every document outside the trash, in ID order,
+	// without loading them all at once. It stops at the first error of fn
+	// and returns it.
+	Iterate(ctx context.Context, fn func(document *entity.Document) error) error
+
+	// IterateMatching is Iterate restricted to the documents that match
+	// the filter
+	IterateMatching(ctx context.Context, filter DocumentFilter, fn func(document *entity.Document) error) error
+
+	// GetDeleted retrieves the documents in the trash
+	GetDeleted(ctx context.Context) ([]*entity.Document, error)
+
+	// GetByID retrieves a document by its ID, including trashed ones
 	GetByID(ctx context.Context, id string) (*entity.Document, error)
 
-	// Create creates a new document
+	// Create creates a new document, failing with
+	// entity.ErrDocumentAlreadyExists if the ID is taken
 	Create(ctx context.Context, document *entity.Document) error
 
 	// Update updates an existing document
 	Update(ctx context.Context, document *entity.Document) error
 
-	// Delete deletes a document
+	// CompareAndUpdate updates a document only if it has not been modified
+	// since expectedUpdatedAt, returning entity.ErrDocumentVersionConflict otherwise
+	CompareAndUpdate(ctx context.Context, document *entity.Document, expectedUpdatedAt time.Time) error
+
+	// Delete permanently deletes a document
 	Delete(ctx context.Context, id string) error
+
+	// LastChange returns a counter that grows with every change to the
+	// stored documents, and when the last change happened
+	LastChange(ctx context.Context) (uint64, time.Time, error)
+
+	// PurgeDeleted permanently deletes documents trashed before the cutoff
+	// and returns the IDs of the removed documents
+	PurgeDeleted(ctx context.Context, cutoff time.Time) ([]string, error)
 }
@@ -0,0 +1,9 @@
+package repository
+
+import "context"
+
+// GroupRepository defines the interface for user group membership
+type GroupRepository interface {
+	// GetGroupsByUserID retrieves the names of the groups a user belongs to
+	GetGroupsByUserID(ctx context.Context, userID string) ([]string, error)
+}
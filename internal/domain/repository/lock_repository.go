@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// LockRepository defines the interface for document edit locks
+type LockRepository interface {
+	// GetByDocumentID retrieves the lock of a document, expired or not
+	GetByDocumentID(ctx context.Context, documentID string) (*entity.Lock, error)
+	// Update atomically replaces the lock of a document with what fn returns
+	// for the current one, which is nil if there is none. Returning nil
+	// removes the lock; returning an error leaves it untouched.
+	Update(ctx context.Context, documentID string, fn func(current *entity.Lock) (*entity.Lock, error)) (*entity.Lock, error)
+	// DeleteExpired removes the locks that expired by now and returns them
+	DeleteExpired(ctx context.Context, now time.Time) ([]*entity.Lock, error)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/pkg/semver"
+)
+
+// SortKey holds the sortable fields of a document and identifies its
+// position in a listing
+type SortKey struct {
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Title     string    `json:"title,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	ID        string    `json:"id"`
+}
+
+// SortKeyOf returns the sort key of a document
+func SortKeyOf(document *entity.Document) SortKey {
+	return SortKey{
+		CreatedAt: document.CreatedAt,
+		UpdatedAt: document.UpdatedAt,
+		Title:     document.Title,
+		Version:   document.Version,
+		ID:        document.ID,
+	}
+}
+
+// Compare returns -1, 0 or 1 depending on whether k sorts before, equal to
+// or after other. Ties on the requested order are broken by creation time
+// and then by ID, so the order is always total.
+func (k SortKey) Compare(other SortKey, order []SortOrder) int {
+	for _, o := range order {
+		c := k.compareField(other, o.Field)
+		if o.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	if c := k.CreatedAt.Compare(other.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(k.ID, other.ID)
+}
+
+// compareField compares a single field in ascending order
+func (k SortKey) compareField(other SortKey, field SortField) int {
+	switch field {
+	case SortByCreatedAt:
+		return k.CreatedAt.Compare(other.CreatedAt)
+	case SortByUpdatedAt:
+		return k.UpdatedAt.Compare(other.UpdatedAt)
+	case SortByTitle:
+		return strings.Compare(strings.ToLower(k.Title), strings.ToLower(other.Title))
+	case SortByVersion:
+		return compareVersions(k.Version, other.Version)
+	}
+	return 0
+}
+
+// compareVersions orders versions by semantic version precedence. Versions
+// that do not parse sort before the others, in lexical order.
+func compareVersions(a, b string) int {
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	switch {
+	case errA == nil && errB == nil:
+		if c := va.Compare(vb); c != 0 {
+			return c
+		}
+		// Equal precedence, e.g. differing build metadata
+		return strings.Compare(a, b)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// PageRequest describes a page of a document listing
+type PageRequest struct {
+	// Limit is the maximum number of documents in the page
+	Limit int
+	// After selects the documents that sort after this key
+	After *SortKey
+	// Before selects the documents that sort before this key
+	Before *SortKey
+}
+
+// DocumentPage is a page of documents
+type DocumentPage struct {
+	Documents []*entity.Document
+	// Next is the key to request the following page, nil on the last page
+	Next *SortKey
+	// Prev is the key to request the preceding page, nil on the first page
+	Prev *SortKey
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// RevisionRepository defines the interface for the document revision history
+type RevisionRepository interface {
+	// Append stores a revision, assigning it the next number of its document
+	Append(ctx context.Context, revision *entity.Revision) error
+
+	// GetByDocumentID retrieves the revisions of a document, oldest first
+	GetByDocumentID(ctx context.Context, documentID string) ([]*entity.Revision, error)
+
+	// Get retrieves one revision of a document
+	Get(ctx context.Context, documentID string, number int) (*entity.Revision, error)
+
+	// DeleteByDocumentID removes the history of a document
+	DeleteByDocumentID(ctx context.Context, documentID string) error
+}
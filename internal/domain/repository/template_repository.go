@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// TemplateRepository defines the interface for the document template repository
+type TemplateRepository interface {
+	// GetAll retrieves all templates, ordered by ID
+	GetAll(ctx context.Context) ([]*entity.Template, error)
+
+	// GetByID retrieves a template by its ID
+	GetByID(ctx context.Context, id string) (*entity.Template, error)
+
+	// Create stores a new template
+	Create(ctx context.Context, template *entity.Template) error
+
+	// Update replaces an existing template
+	Update(ctx context.Context, template *entity.Template) error
+
+	// Delete removes a template
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// UploadSessionRepository defines the interface for staging uploads that
+// arrive in several requests
+type UploadSessionRepository interface {
+	// Create stores a new, empty upload session
+	Create(ctx context.Context, session *entity.UploadSession) error
+
+	// GetByID retrieves an upload session
+	GetByID(ctx context.Context, id string) (*entity.UploadSession, error)
+
+	// Append writes content at the given offset, which must match the
+	// session offset, and returns the updated session. Content beyond the
+	// session length is rejected. Only one append per session runs at a
+	// time; others fail with entity.ErrUploadBusy.
+	Append(ctx context.Context, id string, offset int64, r io.Reader) (*entity.UploadSession, error)
+
+	// Open returns the content staged for a session
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+
+	// Delete removes a session and its staged content
+	Delete(ctx context.Context, id string) error
+
+	// DeleteExpired removes the sessions that expired before now and
+	// returns how many were removed
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// BlobStoreImpl implements BlobStore on the local disk. Content is stored
+// under its SHA-256 as <dir>/<first two hex digits>/<hash>; the file's
+// modification time is when it was last put.
+type BlobStoreImpl struct {
+	dir string
+	// mu keeps a sweep from deleting content while it is put again
+	mu sync.Mutex
+}
+
+// NewBlobStoreImpl creates a BlobStoreImpl rooted at dir, creating it if needed
+func NewBlobStoreImpl(dir string) (repository.BlobStore, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "tmp"), 0o750); err != nil {
+		return nil, err
+	}
+	return &BlobStoreImpl{dir: dir}, nil
+}
+
+// Put streams the content into a temporary file while hashing it and then
+// moves it to its address. Content that is already stored is not copied
+// again, only marked as just stored.
+func (s *BlobStoreImpl) Put(ctx context.Context, r io.Reader, maxSize int64) (*repository.Blob, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, "tmp"), "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, entity.ErrAttachmentTooLarge
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	blob := &repository.Blob{Checksum: hex.EncodeToString(hash.Sum(nil)), Size: size}
+	path := s.path(blob.Checksum)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			return nil, err
+		}
+		return blob, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	// Renaming is atomic, so readers never see partial content and
+	// concurrent uploads of the same content end up with the same file
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// Open opens stored content for reading
+func (s *BlobStoreImpl) Open(ctx context.Context, checksum string) (io.ReadSeekCloser, error) {
+	if !isChecksum(checksum) {
+		return nil, fmt.Errorf("%w: malformed checksum", entity.ErrAttachmentNotFound)
+	}
+	f, err := os.Open(s.path(checksum))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, entity.ErrAttachmentNotFound
+	}
+	return f, err
+}
+
+// Sweep walks the store and deletes the unused content last put before
+// cutoff. Each file is checked again while holding the lock Put takes, so
+// content put during the sweep is kept.
+func (s *BlobStoreImpl) Sweep(ctx context.Context, cutoff time.Time, inUse func(checksum string) bool) (int, error) {
+	prefixes, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, prefix := range prefixes {
+		if !prefix.IsDir() || prefix.Name() == "tmp" {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(s.dir, prefix.Name()))
+		if err != nil {
+			return deleted, err
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return deleted, err
+			}
+			checksum := entry.Name()
+			if !isChecksum(checksum) || checksum[:2] != prefix.Name() {
+				continue
+			}
+			removed, err := s.sweepOne(checksum, cutoff, inUse)
+			if err != nil {
+				return deleted, err
+			}
+			if removed {
+				deleted++
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// sweepOne deletes content if it was last put before cutoff and is not in
+// use, reporting whether it did
+func (s *BlobStoreImpl) sweepOne(checksum string, cutoff time.Time, inUse func(checksum string) bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := s.path(checksum)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.ModTime().Before(cutoff) || inUse(checksum) {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	return true, nil
+}
+
+// path returns where content with the given checksum is stored
+func (s *BlobStoreImpl) path(checksum string) string {
+	return filepath.Join(s.dir, checksum[:2], checksum)
+}
+
+// isChecksum reports whether s is a lowercase hex SHA-256, which also keeps
+// it from escaping the store directory
+func isChecksum(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
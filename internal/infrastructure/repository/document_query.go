@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// Query returns one page of the documents outside the trash that match the query
+func (r *DocumentRepositoryImpl) Query(ctx context.Context, query repository.DocumentQuery) (*repository.DocumentPage, error) {
+	all, err := r.candidates(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]*entity.Document, 0, len(all))
+	for _, doc := range all {
+		if matchesFilter(doc, query.Filter) {
+			documents = append(documents, doc)
+		}
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		return repository.SortKeyOf(documents[i]).Compare(repository.SortKeyOf(documents[j]), query.Sort) < 0
+	})
+
+	return paginate(documents, query.Page, query.Sort), nil
+}
+
+// candidates returns the documents a filter may match. Tag filters are
+// answered from the tag index instead of scanning every document.
+func (r *DocumentRepositoryImpl) candidates(ctx context.Context, f repository.DocumentFilter) ([]*entity.Document, error) {
+	if len(f.Tags) == 0 {
+		return r.GetAll(ctx)
+	}
+
+	ids := r.tags.match(f.Tags, f.TagMode != repository.TagMatchAny)
+	documents := make([]*entity.Document, 0, len(ids))
+	for _, id := range ids {
+		doc, err := r.cache.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			// Expired from the cache since it was indexed
+			r.tags.remove(id)
+			continue
+		}
+		if !doc.IsDeleted() {
+			documents = append(documents, doc)
+		}
+	}
+	return documents, nil
+}
+
+// IterateMatching walks the documents that match the filter one at a time.
+// Tag filters walk the tag index instead of every document.
+func (r *DocumentRepositoryImpl) IterateMatching(ctx context.Context, f repository.DocumentFilter, fn func(document *entity.Document) error) error {
+	match := func(document *entity.Document) error {
+		if !matchesFilter(document, f) {
+			return nil
+		}
+		return fn(document)
+	}
+	if len(f.Tags) == 0 {
+		return r.Iterate(ctx, match)
+	}
+
+	ids := r.tags.match(f.Tags, f.TagMode != repository.TagMatchAny)
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		doc, err := r.cache.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Removed or expired since it was indexed
+		if doc == nil || doc.IsDeleted() {
+			continue
+		}
+		if err := match(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchesFilter evaluates a filter against a document
+func matchesFilter(d *entity.Document, f repository.DocumentFilter) bool {
+	if f.Title != "" && !strings.EqualFold(d.Title, f.Title) {
+		return false
+	}
+	if f.TitleContains != "" && !strings.Contains(strings.ToLower(d.Title), strings.ToLower(f.TitleContains)) {
+		return false
+	}
+	if f.Version != "" && d.Version != f.Version {
+		return false
+	}
+	if f.ContributorID != "" && !hasContributor(d, f.ContributorID) {
+		return false
+	}
+	if len(f.Tags) > 0 && !hasTags(d, f.Tags, f.TagMode) {
+		return false
+	}
+	if f.ReadableBy != nil && !d.Allows(*f.ReadableBy, entity.PermissionRead) {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !d.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !d.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	if !f.UpdatedAfter.IsZero() && !d.UpdatedAt.After(f.UpdatedAfter) {
+		return false
+	}
+	if !f.UpdatedBefore.IsZero() && !d.UpdatedAt.Before(f.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+// hasContributor reports whether the user contributed to the document
+func hasContributor(d *entity.Document, userID string) bool {
+	for _, contributor := range d.Contributors {
+		if contributor.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTags reports whether the document carries all of the tags, or any of
+// them in TagMatchAny mode
+func hasTags(d *entity.Document, tags []string, mode repository.TagMode) bool {
+	for _, tag := range tags {
+		if d.HasTag(tag) == (mode == repository.TagMatchAny) {
+			return mode == repository.TagMatchAny
+		}
+	}
+	return mode != repository.TagMatchAny
+}
+
+// paginate cuts a page out of documents already sorted in the given order
+func paginate(documents []*entity.Document, page repository.PageRequest, order []repository.SortOrder) *repository.DocumentPage {
+	start, end := 0, len(documents)
+	switch {
+	case page.After != nil:
+		start = sort.Search(len(documents), func(i int) bool {
+			return repository.SortKeyOf(documents[i]).Compare(*page.After, order) > 0
+		})
+		end = min(start+page.Limit, len(documents))
+	case page.Before != nil:
+		end = sort.Search(len(documents), func(i int) bool {
+			return repository.SortKeyOf(documents[i]).Compare(*page.Before, order) >= 0
+		})
+		start = max(end-page.Limit, 0)
+	default:
+		end = min(page.Limit, len(documents))
+	}
+
+	result := &repository.DocumentPage{Documents: documents[start:end]}
+	if end < len(documents) && end > 0 {
+		next := repository.SortKeyOf(documents[end-1])
+		result.Next = &next
+	}
+	if start > 0 && start < len(documents) {
+		prev := repository.SortKeyOf(documents[start])
+		result.Prev = &prev
+	}
+	return result
+}
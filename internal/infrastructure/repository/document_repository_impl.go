@@ -3,6 +3,10 @@ package repository
 import (
 	"context"
 	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"frontend-challenge/internal/domain/entity"
 	"frontend-challenge/internal/domain/repository"
@@ -13,18 +17,37 @@ import (
 // DocumentRepositoryImpl implements DocumentRepository
 type DocumentRepositoryImpl struct {
 	cache repository.CacheRepository
+	// mu serializes writes so conditional updates are atomic
+	mu sync.Mutex
+	// index is the full-text index, kept in sync on every write
+	index *searchIndex
+	// tags indexes documents by tag, kept in sync like index
+	tags *tagIndex
+	// changes counts writes; it starts from the clock so that its values
+	// are not reused after a restart
+	changes atomic.Uint64
+	// changedAt is the time of the last write in Unix nanoseconds
+	changedAt atomic.Int64
+	// expiredAt is the last expiry from the cache counted as a change
+	expiredAt time.Time
 	// In a real implementation, this would hold a database connection
 	// For now we simulate with in-memory data
 }
 
 // NewDocumentRepositoryImpl creates a new DocumentRepositoryImpl instance
 func NewDocumentRepositoryImpl(cache repository.CacheRepository) repository.DocumentRepository {
-	return &DocumentRepositoryImpl{
+	r := &DocumentRepositoryImpl{
 		cache: cache,
+		index: newSearchIndex(),
+		tags:  newTagIndex(),
 	}
+	now := time.Now()
+	r.changes.Store(uint64(now.UnixNano()))
+	r.changedAt.Store(now.UnixNano())
+	return r
 }
 
-// GetAll returns all documents (simulated)
+// GetAll returns all documents outside the trash (simulated)
 func (r *DocumentRepositoryImpl) GetAll(ctx context.Context) ([]*entity.Document, error) {
 	// First try to fetch from cache
 	cachedDocs, err := r.cache.GetAll(ctx)
@@ -32,63 +55,291 @@ func (r *DocumentRepositoryImpl) GetAll(ctx context.Context) ([]*entity.Document
 		return nil, err
 	}
 
-	// If there are cached documents, return them
-	if len(cachedDocs) > 0 {
-		return cachedDocs, nil
+	// If not cached, generate some simulated ones
+	if len(cachedDocs) == 0 {
+		if cachedDocs, err = r.seed(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the cached documents not in the trash
+	documents := make([]*entity.Document, 0, len(cachedDocs))
+	for _, doc := range cachedDocs {
+		if !doc.IsDeleted() {
+			documents = append(documents, doc)
+		}
+	}
+	return documents, nil
+}
+
+// seed stores simulated documents if the cache is empty, and returns the
+// cached documents
+func (r *DocumentRepositoryImpl) seed(ctx context.Context) ([]*entity.Document, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another request may have seeded the cache meanwhile
+	cachedDocs, err := r.cache.GetAll(ctx)
+	if err != nil || len(cachedDocs) > 0 {
+		return cachedDocs, err
 	}
 
-	// If not cached, generate some simulated ones
-	var documents []*entity.Document
 	count := 1 + rand.Intn(20)
 	for i := 0; i < count; i++ {
 		doc := r.generateRandomDocument()
-		documents = append(documents, doc)
+		cachedDocs = append(cachedDocs, doc)
 
 		// Store in cache
 		r.cache.Set(ctx, doc.ID, doc)
+		r.syncIndex(doc)
+	}
+	r.touch()
+	return cachedDocs, nil
+}
+
+// Iterate walks the cache one document at a time
+func (r *DocumentRepositoryImpl) Iterate(ctx context.Context, fn func(document *entity.Document) error) error {
+	var fnErr error
+	err := r.cache.Range(ctx, func(_ string, document *entity.Document) bool {
+		if document.IsDeleted() {
+			return true
+		}
+		fnErr = fn(document)
+		return fnErr == nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// GetDeleted returns the documents in the trash
+func (r *DocumentRepositoryImpl) GetDeleted(ctx context.Context) ([]*entity.Document, error) {
+	cachedDocs, err := r.cache.GetAll(ctx)
+	if err != nil {
+		return nil, err
 	}
 
+	documents := []*entity.Document{}
+	for _, doc := range cachedDocs {
+		if doc.IsDeleted() {
+			documents = append(documents, doc)
+		}
+	}
 	return documents, nil
 }
 
-// GetByID returns a document by ID (simulated)
+// GetByID returns a document by ID
 func (r *DocumentRepositoryImpl) GetByID(ctx context.Context, id string) (*entity.Document, error) {
-	// First try to fetch from cache
 	cachedDoc, err := r.cache.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if cachedDoc != nil {
-		return cachedDoc, nil
+	if cachedDoc == nil {
+		return nil, entity.ErrDocumentNotFound
 	}
 
-	// If not cached, generate a random one
-	doc := r.generateRandomDocument()
-	doc.ID = id // Usar el ID solicitado
-
-	// Store in cache
-	r.cache.Set(ctx, id, doc)
-
-	return doc, nil
+	return cachedDoc, nil
 }
 
 // Create creates a new document
 func (r *DocumentRepositoryImpl) Create(ctx context.Context, document *entity.Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cache.Exists(ctx, document.ID) {
+		return entity.ErrDocumentAlreadyExists
+	}
+
 	// Store in cache
-	return r.cache.Set(ctx, document.ID, document)
+	if err := r.cache.Set(ctx, document.ID, document); err != nil {
+		return err
+	}
+	r.syncIndex(document)
+	r.touch()
+	return nil
 }
 
 // Update updates an existing document (simulated)
 func (r *DocumentRepositoryImpl) Update(ctx context.Context, document *entity.Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Update in cache
-	return r.cache.Set(ctx, document.ID, document)
+	if err := r.cache.Set(ctx, document.ID, document); err != nil {
+		return err
+	}
+	r.syncIndex(document)
+	r.touch()
+	return nil
+}
+
+// CompareAndUpdate updates a document only if nobody modified it since expectedUpdatedAt
+func (r *DocumentRepositoryImpl) CompareAndUpdate(ctx context.Context, document *entity.Document, expectedUpdatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.cache.Get(ctx, document.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return entity.ErrDocumentNotFound
+	}
+	if !current.UpdatedAt.Equal(expectedUpdatedAt) {
+		return entity.ErrDocumentVersionConflict
+	}
+
+	if err := r.cache.Set(ctx, document.ID, document); err != nil {
+		return err
+	}
+	r.syncIndex(document)
+	r.touch()
+	return nil
 }
 
 // Delete removes a document (simulated)
 func (r *DocumentRepositoryImpl) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Remove from cache
-	return r.cache.Delete(ctx, id)
+	if err := r.cache.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.index.remove(id)
+	r.tags.remove(id)
+	r.touch()
+	return nil
+}
+
+// PurgeDeleted permanently removes documents trashed before the cutoff
+func (r *DocumentRepositoryImpl) PurgeDeleted(ctx context.Context, cutoff time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cachedDocs, err := r.cache.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []string
+	for _, doc := range cachedDocs {
+		if doc.IsDeleted() && doc.DeletedAt.Before(cutoff) {
+			if err := r.cache.Delete(ctx, doc.ID); err != nil {
+				return purged, err
+			}
+			r.index.remove(doc.ID)
+			r.tags.remove(doc.ID)
+			purged = append(purged, doc.ID)
+		}
+	}
+	if len(purged) > 0 {
+		r.touch()
+	}
+	return purged, nil
+}
+
+// LastChange returns the write counter and the time of the last write.
+// Documents expiring from the cache count as a write, and an empty cache is
+// seeded first, as listing it would, so that the counter covers what the
+// next listing returns.
+func (r *DocumentRepositoryImpl) LastChange(ctx context.Context) (uint64, time.Time, error) {
+	if r.cache.Count(ctx) == 0 {
+		if _, err := r.seed(ctx); err != nil {
+			return 0, time.Time{}, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if expiredAt := r.cache.LastExpiry(ctx); expiredAt.After(r.expiredAt) {
+		r.expiredAt = expiredAt
+		r.changes.Add(1)
+		if expiredAt.UnixNano() > r.changedAt.Load() {
+			r.changedAt.Store(expiredAt.UnixNano())
+		}
+	}
+	return r.changes.Load(), time.Unix(0, r.changedAt.Load()), nil
+}
+
+// touch records a write
+func (r *DocumentRepositoryImpl) touch() {
+	r.changedAt.Store(time.Now().UnixNano())
+	r.changes.Add(1)
+}
+
+// Search ranks the documents outside the trash against a free-text query
+func (r *DocumentRepositoryImpl) Search(ctx context.Context, query string, limit int) ([]repository.SearchHit, error) {
+	hits := []repository.SearchHit{}
+	for _, result := range r.index.search(query, 0) {
+		doc, err := r.cache.Get(ctx, result.id)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			// Expired from the cache since it was indexed
+			r.index.remove(result.id)
+			continue
+		}
+
+		hits = append(hits, repository.SearchHit{
+			Document:   doc,
+			Score:      result.score,
+			Highlights: highlight(doc, query),
+		})
+		if limit > 0 && len(hits) == limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+// syncIndex indexes a stored document, or drops it from the indexes when trashed
+func (r *DocumentRepositoryImpl) syncIndex(document *entity.Document) {
+	if document.IsDeleted() {
+		r.index.remove(document.ID)
+		r.tags.remove(document.ID)
+		return
+	}
+	r.index.add(document)
+	r.tags.add(document)
+}
+
+// TagCounts returns the usage of every tag among the documents matching
+// the filter, most used first. Only tagged documents are looked at.
+func (r *DocumentRepositoryImpl) TagCounts(ctx context.Context, filter repository.DocumentFilter) ([]repository.TagCount, error) {
+	usage := make(map[string]int)
+	for _, id := range r.tags.ids() {
+		doc, err := r.cache.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			// Expired from the cache since it was indexed
+			r.tags.remove(id)
+			continue
+		}
+		if doc.IsDeleted() || !matchesFilter(doc, filter) {
+			continue
+		}
+		for _, tag := range doc.Tags {
+			usage[tag]++
+		}
+	}
+
+	counts := make([]repository.TagCount, 0, len(usage))
+	for tag, count := range usage {
+		counts = append(counts, repository.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+	return counts, nil
 }
 
 // generateRandomDocument generates a random document for simulation
@@ -102,14 +353,25 @@ func (r *DocumentRepositoryImpl) generateRandomDocument() *entity.Document {
 	// Add random attachments
 	attachmentCount := 1 + rand.Intn(4)
 	for j := 0; j < attachmentCount; j++ {
-		doc.AddAttachment(gofakeit.BeerStyle())
+		doc.AddAttachment(entity.NewAttachment(gofakeit.BeerStyle()))
+	}
+
+	// Add a few random tags
+	tags := []string{"draft", "review", "final", "internal", "public", "archived"}
+	for _, i := range rand.Perm(len(tags))[:rand.Intn(3)] {
+		_ = doc.AddTags(tags[i])
 	}
 
-	// Add random contributors
+	// Add random contributors, the first one owning the document
+	roles := []string{entity.RoleEditor, entity.RoleViewer}
 	contributorCount := 1 + rand.Intn(4)
 	for j := 0; j < contributorCount; j++ {
 		user := entity.NewUser(gofakeit.UUID(), gofakeit.Name())
-		doc.AddContributor(*user)
+		role := roles[rand.Intn(len(roles))]
+		if j == 0 {
+			role = entity.RoleOwner
+		}
+		_ = doc.AddContributor(*user, role)
 	}
 
 	return doc
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"frontend-challenge/internal/domain/repository"
+)
+
+// GroupRepositoryImpl implements GroupRepository from a JSON file mapping
+// group names to the IDs of their members:
+//
+//	{"engineering": ["3f1c...", "8a2d..."], "legal": ["77b0..."]}
+type GroupRepositoryImpl struct {
+	// groups maps a user ID to its sorted group names; it is read-only
+	// once loaded
+	groups map[string][]string
+}
+
+// NewGroupRepositoryImpl loads group membership from a file. Without a
+// file nobody belongs to any group.
+func NewGroupRepositoryImpl(path string) (repository.GroupRepository, error) {
+	r := &GroupRepositoryImpl{groups: make(map[string][]string)}
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string][]string
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for group, userIDs := range members {
+		for _, userID := range userIDs {
+			r.groups[userID] = append(r.groups[userID], group)
+		}
+	}
+	for _, groups := range r.groups {
+		sort.Strings(groups)
+	}
+	return r, nil
+}
+
+// GetGroupsByUserID returns the groups of a user
+func (r *GroupRepositoryImpl) GetGroupsByUserID(ctx context.Context, userID string) ([]string, error) {
+	return r.groups[userID], nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// LockRepositoryImpl implements LockRepository in memory
+type LockRepositoryImpl struct {
+	mu    sync.Mutex
+	locks map[string]*entity.Lock
+}
+
+// NewLockRepositoryImpl creates a new LockRepositoryImpl instance
+func NewLockRepositoryImpl() repository.LockRepository {
+	return &LockRepositoryImpl{
+		locks: make(map[string]*entity.Lock),
+	}
+}
+
+// GetByDocumentID returns a copy of the lock of a document
+func (r *LockRepositoryImpl) GetByDocumentID(ctx context.Context, documentID string) (*entity.Lock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lock, ok := r.locks[documentID]
+	if !ok {
+		return nil, entity.ErrLockNotFound
+	}
+	return lock.Clone(), nil
+}
+
+// Update replaces the lock of a document while holding the repository lock
+func (r *LockRepositoryImpl) Update(ctx context.Context, documentID string, fn func(current *entity.Lock) (*entity.Lock, error)) (*entity.Lock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var current *entity.Lock
+	if lock, ok := r.locks[documentID]; ok {
+		current = lock.Clone()
+	}
+	next, err := fn(current)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		delete(r.locks, documentID)
+		return nil, nil
+	}
+	r.locks[documentID] = next.Clone()
+	return next, nil
+}
+
+// DeleteExpired removes expired locks, returning them ordered by document ID
+func (r *LockRepositoryImpl) DeleteExpired(ctx context.Context, now time.Time) ([]*entity.Lock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []*entity.Lock
+	for documentID, lock := range r.locks {
+		if lock.Expired(now) {
+			expired = append(expired, lock)
+			delete(r.locks, documentID)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].DocumentID < expired[j].DocumentID })
+	return expired, nil
+}
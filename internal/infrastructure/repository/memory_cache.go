@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -9,12 +10,17 @@ import (
 	"frontend-challenge/internal/domain/repository"
 )
 
-// MemoryCache implements CacheRepository using in-memory storage
+// MemoryCache implements CacheRepository using in-memory storage.
+// Documents in the trash do not expire: the trash retention purge removes
+// them.
 type MemoryCache struct {
 	documents map[string]*entity.Document
 	mutex     sync.RWMutex
 	ttl       time.Duration
-	expiry    map[string]time.Time
+	// expiry holds when each document expires, the zero time for never
+	expiry map[string]time.Time
+	// cleanedUp is when the last document removed by the cleanup expired
+	cleanedUp time.Time
 }
 
 // NewMemoryCache creates a new MemoryCache instance
@@ -37,11 +43,21 @@ func (c *MemoryCache) Set(ctx context.Context, key string, document *entity.Docu
 	defer c.mutex.Unlock()
 
 	c.documents[key] = document
-	c.expiry[key] = time.Now().Add(c.ttl)
+	if document.IsDeleted() {
+		c.expiry[key] = time.Time{}
+	} else {
+		c.expiry[key] = time.Now().Add(c.ttl)
+	}
 
 	return nil
 }
 
+// expired reports whether a document has expired; callers hold the lock
+func (c *MemoryCache) expired(key string, now time.Time) bool {
+	expiry := c.expiry[key]
+	return !expiry.IsZero() && now.After(expiry)
+}
+
 // Get retrieves a document from the cache
 func (c *MemoryCache) Get(ctx context.Context, key string) (*entity.Document, error) {
 	c.mutex.RLock()
@@ -53,7 +69,7 @@ func (c *MemoryCache) Get(ctx context.Context, key string) (*entity.Document, er
 	}
 
 	// Check if it has expired
-	if time.Now().After(c.expiry[key]) {
+	if c.expired(key, time.Now()) {
 		return nil, nil
 	}
 
@@ -70,7 +86,7 @@ func (c *MemoryCache) GetAll(ctx context.Context) ([]*entity.Document, error) {
 
 	for key, document := range c.documents {
 		// Include only non-expired documents
-		if now.Before(c.expiry[key]) {
+		if !c.expired(key, now) {
 			documents = append(documents, document)
 		}
 	}
@@ -78,6 +94,36 @@ func (c *MemoryCache) GetAll(ctx context.Context) ([]*entity.Document, error) {
 	return documents, nil
 }
 
+// Range iterates over a snapshot of the keys, fetching each document only
+// when its turn comes
+func (c *MemoryCache) Range(ctx context.Context, fn func(key string, document *entity.Document) bool) error {
+	c.mutex.RLock()
+	keys := make([]string, 0, len(c.documents))
+	for key := range c.documents {
+		keys = append(keys, key)
+	}
+	c.mutex.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		document, err := c.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		// Deleted or expired since the snapshot
+		if document == nil {
+			continue
+		}
+		if !fn(key, document) {
+			return nil
+		}
+	}
+	return nil
+}
+
 // Delete removes a document from the cache
 func (c *MemoryCache) Delete(ctx context.Context, key string) error {
 	c.mutex.Lock()
@@ -111,7 +157,7 @@ func (c *MemoryCache) Exists(ctx context.Context, key string) bool {
 	}
 
 	// Check if it has expired
-	return time.Now().Before(c.expiry[key])
+	return !c.expired(key, time.Now())
 }
 
 // Count returns the number of documents in the cache
@@ -122,8 +168,8 @@ func (c *MemoryCache) Count(ctx context.Context) int {
 	count := 0
 	now := time.Now()
 
-	for _, expiry := range c.expiry {
-		if now.Before(expiry) {
+	for key := range c.expiry {
+		if !c.expired(key, now) {
 			count++
 		}
 	}
@@ -131,6 +177,22 @@ func (c *MemoryCache) Count(ctx context.Context) int {
 	return count
 }
 
+// LastExpiry returns when the last document to expire did so, including
+// the ones the cleanup removed
+func (c *MemoryCache) LastExpiry(ctx context.Context) time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	last := c.cleanedUp
+	now := time.Now()
+	for key, expiry := range c.expiry {
+		if c.expired(key, now) && expiry.After(last) {
+			last = expiry
+		}
+	}
+	return last
+}
+
 // startCleanup starts the automatic cleanup of expired entries
 func (c *MemoryCache) startCleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -147,8 +209,11 @@ func (c *MemoryCache) cleanupExpired() {
 	defer c.mutex.Unlock()
 
 	now := time.Now()
-	for key, expiry := range c.expiry {
-		if now.After(expiry) {
+	for key := range c.expiry {
+		if c.expired(key, now) {
+			if c.expiry[key].After(c.cleanedUp) {
+				c.cleanedUp = c.expiry[key]
+			}
 			delete(c.documents, key)
 			delete(c.expiry, key)
 		}
@@ -164,8 +229,8 @@ func (c *MemoryCache) GetStats() map[string]interface{} {
 	activeCount := 0
 	expiredCount := 0
 
-	for _, expiry := range c.expiry {
-		if now.Before(expiry) {
+	for key := range c.expiry {
+		if !c.expired(key, now) {
 			activeCount++
 		} else {
 			expiredCount++
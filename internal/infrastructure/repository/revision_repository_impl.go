@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// RevisionRepositoryImpl implements RevisionRepository in memory
+type RevisionRepositoryImpl struct {
+	mu        sync.RWMutex
+	revisions map[string][]*entity.Revision
+}
+
+// NewRevisionRepositoryImpl creates a new RevisionRepositoryImpl instance
+func NewRevisionRepositoryImpl() repository.RevisionRepository {
+	return &RevisionRepositoryImpl{
+		revisions: make(map[string][]*entity.Revision),
+	}
+}
+
+// Append stores a revision with the next number of its document
+func (r *RevisionRepositoryImpl) Append(ctx context.Context, revision *entity.Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := r.revisions[revision.DocumentID]
+	revision.Number = len(history) + 1
+	r.revisions[revision.DocumentID] = append(history, revision)
+	return nil
+}
+
+// GetByDocumentID returns the revisions of a document, oldest first
+func (r *RevisionRepositoryImpl) GetByDocumentID(ctx context.Context, documentID string) ([]*entity.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]*entity.Revision{}, r.revisions[documentID]...), nil
+}
+
+// Get returns one revision of a document
+func (r *RevisionRepositoryImpl) Get(ctx context.Context, documentID string, number int) (*entity.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.revisions[documentID]
+	if number < 1 || number > len(history) {
+		return nil, entity.ErrRevisionNotFound
+	}
+	return history[number-1], nil
+}
+
+// DeleteByDocumentID removes the history of a document
+func (r *RevisionRepositoryImpl) DeleteByDocumentID(ctx context.Context, documentID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.revisions, documentID)
+	return nil
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// Searchable document fields
+const (
+	fieldTitle        = "title"
+	fieldAttachments  = "attachments"
+	fieldContributors = "contributors"
+)
+
+// fieldWeights boosts matches in some fields over others (BM25F)
+var fieldWeights = map[string]float64{
+	fieldTitle:        3.0,
+	fieldAttachments:  1.0,
+	fieldContributors: 1.0,
+}
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// prefixPenalty scales the score of terms that only match as a prefix
+	prefixPenalty = 0.5
+)
+
+// searchIndex is an in-memory inverted index over document text
+type searchIndex struct {
+	mu sync.Mutex
+	// postings maps a term to the weighted frequency per document ID
+	postings map[string]map[string]float64
+	// lengths holds the weighted token count of every indexed document
+	lengths map[string]float64
+	// docTerms remembers the terms of each document so it can be removed
+	docTerms map[string][]string
+	// terms is the sorted vocabulary used for prefix lookups
+	terms      []string
+	termsDirty bool
+	totalLen   float64
+}
+
+// scoredID is a search result before the document is loaded
+type scoredID struct {
+	id    string
+	score float64
+}
+
+// newSearchIndex creates an empty index
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string]map[string]float64),
+		lengths:  make(map[string]float64),
+		docTerms: make(map[string][]string),
+	}
+}
+
+// documentFields returns the searchable text of a document per field
+func documentFields(d *entity.Document) map[string][]string {
+	names := make([]string, len(d.Contributors))
+	for i, contributor := range d.Contributors {
+		names[i] = contributor.Name
+	}
+	attachments := make([]string, len(d.Attachments))
+	for i, attachment := range d.Attachments {
+		attachments[i] = attachment.Name
+	}
+	return map[string][]string{
+		fieldTitle:        {d.Title},
+		fieldAttachments:  attachments,
+		fieldContributors: names,
+	}
+}
+
+// tokenize splits text into case-folded terms
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// add indexes a document, replacing any previous version of it
+func (idx *searchIndex) add(d *entity.Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(d.ID)
+
+	frequencies := make(map[string]float64)
+	length := 0.0
+	for field, values := range documentFields(d) {
+		weight := fieldWeights[field]
+		for _, value := range values {
+			for _, term := range tokenize(value) {
+				frequencies[term] += weight
+				length += weight
+			}
+		}
+	}
+
+	terms := make([]string, 0, len(frequencies))
+	for term, frequency := range frequencies {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]float64)
+			idx.termsDirty = true
+		}
+		idx.postings[term][d.ID] = frequency
+		terms = append(terms, term)
+	}
+	idx.docTerms[d.ID] = terms
+	idx.lengths[d.ID] = length
+	idx.totalLen += length
+}
+
+// remove drops a document from the index
+func (idx *searchIndex) remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(id)
+}
+
+func (idx *searchIndex) removeLocked(id string) {
+	for _, term := range idx.docTerms[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+			idx.termsDirty = true
+		}
+	}
+	idx.totalLen -= idx.lengths[id]
+	delete(idx.docTerms, id)
+	delete(idx.lengths, id)
+}
+
+// search ranks indexed documents against the query with BM25. Every query
+// term also matches indexed terms it is a prefix of, at a reduced weight.
+func (idx *searchIndex) search(query string, limit int) []scoredID {
+	queryTerms := tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	// The vocabulary is re-sorted lazily, so searching may write
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.termsDirty {
+		idx.terms = idx.terms[:0]
+		for term := range idx.postings {
+			idx.terms = append(idx.terms, term)
+		}
+		sort.Strings(idx.terms)
+		idx.termsDirty = false
+	}
+
+	docCount := float64(len(idx.lengths))
+	if docCount == 0 {
+		return nil
+	}
+	avgLen := idx.totalLen / docCount
+
+	scores := make(map[string]float64)
+	for _, queryTerm := range queryTerms {
+		for _, term := range idx.expand(queryTerm) {
+			postings := idx.postings[term]
+			df := float64(len(postings))
+			idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+			boost := 1.0
+			if term != queryTerm {
+				boost = prefixPenalty
+			}
+			for id, tf := range postings {
+				norm := bm25K1 * (1 - bm25B + bm25B*idx.lengths[id]/avgLen)
+				scores[id] += boost * idf * tf * (bm25K1 + 1) / (tf + norm)
+			}
+		}
+	}
+
+	results := make([]scoredID, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, scoredID{id: id, score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].id < results[j].id
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// expand returns the indexed terms starting with prefix, the exact term first
+func (idx *searchIndex) expand(prefix string) []string {
+	start := sort.SearchStrings(idx.terms, prefix)
+	var matches []string
+	for i := start; i < len(idx.terms) && strings.HasPrefix(idx.terms[i], prefix); i++ {
+		matches = append(matches, idx.terms[i])
+	}
+	return matches
+}
+
+// highlight returns the field values of the document that match the query,
+// HTML-escaped with the matching words wrapped in <mark> tags
+func highlight(d *entity.Document, query string) map[string][]string {
+	queryTerms := tokenize(query)
+	highlights := make(map[string][]string)
+	for field, values := range documentFields(d) {
+		for _, value := range values {
+			if snippet, ok := markTerms(value, queryTerms); ok {
+				highlights[field] = append(highlights[field], snippet)
+			}
+		}
+	}
+	return highlights
+}
+
+// markTerms wraps the words of text that start with any of the terms
+func markTerms(text string, terms []string) (string, bool) {
+	var b strings.Builder
+	matched := false
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsNumber(runes[i]) {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsNumber(runes[j])) {
+			j++
+		}
+		word := string(runes[i:j])
+		if hasTermPrefix(strings.ToLower(word), terms) {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+			matched = true
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		i = j
+	}
+	return b.String(), matched
+}
+
+// hasTermPrefix reports whether word starts with any of the terms
+func hasTermPrefix(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
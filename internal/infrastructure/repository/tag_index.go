@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// tagIndex maps tags to the documents carrying them
+type tagIndex struct {
+	mu sync.RWMutex
+	// postings maps a tag to the IDs of its documents
+	postings map[string]map[string]struct{}
+	// docTags remembers the tags of each document so it can be removed
+	docTags map[string][]string
+}
+
+// newTagIndex creates an empty index
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		postings: make(map[string]map[string]struct{}),
+		docTags:  make(map[string][]string),
+	}
+}
+
+// add indexes a document, replacing what was indexed for it before
+func (t *tagIndex) add(d *entity.Document) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.removeLocked(d.ID)
+	if len(d.Tags) == 0 {
+		return
+	}
+	for _, tag := range d.Tags {
+		ids, ok := t.postings[tag]
+		if !ok {
+			ids = make(map[string]struct{})
+			t.postings[tag] = ids
+		}
+		ids[d.ID] = struct{}{}
+	}
+	t.docTags[d.ID] = append([]string(nil), d.Tags...)
+}
+
+// remove drops a document from the index
+func (t *tagIndex) remove(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.removeLocked(id)
+}
+
+func (t *tagIndex) removeLocked(id string) {
+	for _, tag := range t.docTags[id] {
+		delete(t.postings[tag], id)
+		if len(t.postings[tag]) == 0 {
+			delete(t.postings, tag)
+		}
+	}
+	delete(t.docTags, id)
+}
+
+// match returns the IDs of the documents carrying all of the tags, or any
+// of them when all is false
+func (t *tagIndex) match(tags []string, all bool) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if all {
+		// Walk the rarest tag and check the others
+		sorted := append([]string(nil), tags...)
+		sort.Slice(sorted, func(i, j int) bool { return len(t.postings[sorted[i]]) < len(t.postings[sorted[j]]) })
+		var ids []string
+	candidates:
+		for id := range t.postings[sorted[0]] {
+			for _, tag := range sorted[1:] {
+				if _, ok := t.postings[tag][id]; !ok {
+					continue candidates
+				}
+			}
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	seen := make(map[string]struct{})
+	var ids []string
+	for _, tag := range tags {
+		for id := range t.postings[tag] {
+			if _, dup := seen[id]; !dup {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// ids returns the IDs of all tagged documents
+func (t *tagIndex) ids() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	ids := make([]string, 0, len(t.docTags))
+	for id := range t.docTags {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// TemplateRepositoryImpl implements TemplateRepository in memory
+type TemplateRepositoryImpl struct {
+	mu        sync.RWMutex
+	templates map[string]*entity.Template
+}
+
+// NewTemplateRepositoryImpl creates a new TemplateRepositoryImpl instance
+func NewTemplateRepositoryImpl() repository.TemplateRepository {
+	return &TemplateRepositoryImpl{
+		templates: make(map[string]*entity.Template),
+	}
+}
+
+// GetAll returns copies of all templates, ordered by ID
+func (r *TemplateRepositoryImpl) GetAll(ctx context.Context) ([]*entity.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]*entity.Template, 0, len(r.templates))
+	for _, template := range r.templates {
+		templates = append(templates, template.Clone())
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+	return templates, nil
+}
+
+// GetByID returns a copy of a template
+func (r *TemplateRepositoryImpl) GetByID(ctx context.Context, id string) (*entity.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	template, ok := r.templates[id]
+	if !ok {
+		return nil, entity.ErrTemplateNotFound
+	}
+	return template.Clone(), nil
+}
+
+// Create stores a new template
+func (r *TemplateRepositoryImpl) Create(ctx context.Context, template *entity.Template) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[template.ID]; exists {
+		return entity.ErrTemplateAlreadyExists
+	}
+	r.templates[template.ID] = template.Clone()
+	return nil
+}
+
+// Update replaces an existing template
+func (r *TemplateRepositoryImpl) Update(ctx context.Context, template *entity.Template) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[template.ID]; !exists {
+		return entity.ErrTemplateNotFound
+	}
+	r.templates[template.ID] = template.Clone()
+	return nil
+}
+
+// Delete removes a template
+func (r *TemplateRepositoryImpl) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[id]; !exists {
+		return entity.ErrTemplateNotFound
+	}
+	delete(r.templates, id)
+	return nil
+}
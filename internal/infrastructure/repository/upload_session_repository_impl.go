@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// UploadSessionRepositoryImpl implements UploadSessionRepository with the
+// sessions in memory and their content staged in files under dir
+type UploadSessionRepositoryImpl struct {
+	dir      string
+	mu       sync.Mutex
+	sessions map[string]*stagedUpload
+}
+
+// stagedUpload is a session and whether a request is appending to it
+type stagedUpload struct {
+	session entity.UploadSession
+	busy    bool
+}
+
+// NewUploadSessionRepositoryImpl creates an UploadSessionRepositoryImpl
+// staging content in dir, creating it if needed. Sessions do not outlive the
+// process, so files staged by an earlier run are removed.
+func NewUploadSessionRepositoryImpl(dir string) (repository.UploadSessionRepository, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return nil, err
+		}
+	}
+	return &UploadSessionRepositoryImpl{
+		dir:      dir,
+		sessions: make(map[string]*stagedUpload),
+	}, nil
+}
+
+// Create stores a new session with an empty staging file
+func (r *UploadSessionRepositoryImpl) Create(ctx context.Context, session *entity.UploadSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; exists {
+		return fmt.Errorf("upload %s already exists", session.ID)
+	}
+	f, err := os.OpenFile(r.path(session.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	r.sessions[session.ID] = &stagedUpload{session: *session}
+	return nil
+}
+
+// GetByID returns a copy of a session
+func (r *UploadSessionRepositoryImpl) GetByID(ctx context.Context, id string) (*entity.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	staged, ok := r.sessions[id]
+	if !ok {
+		return nil, entity.ErrUploadNotFound
+	}
+	session := staged.session
+	return &session, nil
+}
+
+// Append writes to the end of the staging file. The session lock is only
+// held to claim the session, so slow clients do not block other uploads.
+func (r *UploadSessionRepositoryImpl) Append(ctx context.Context, id string, offset int64, content io.Reader) (*entity.UploadSession, error) {
+	r.mu.Lock()
+	staged, ok := r.sessions[id]
+	switch {
+	case !ok:
+		r.mu.Unlock()
+		return nil, entity.ErrUploadNotFound
+	case staged.busy:
+		r.mu.Unlock()
+		return nil, entity.ErrUploadBusy
+	case staged.session.Offset != offset:
+		r.mu.Unlock()
+		return nil, entity.ErrUploadOffsetMismatch
+	}
+	staged.busy = true
+	remaining := staged.session.Length - offset
+	r.mu.Unlock()
+
+	written, err := r.write(id, content, offset, remaining)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Whatever was written is kept, so the client can resume from there
+	staged.session.Offset += written
+	staged.busy = false
+	session := staged.session
+	return &session, err
+}
+
+// write appends at most limit bytes of content to the staging file, which
+// holds offset bytes. Content holding more is discarded entirely.
+func (r *UploadSessionRepositoryImpl) write(id string, content io.Reader, offset, limit int64) (int64, error) {
+	f, err := os.OpenFile(r.path(id), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(f, io.LimitReader(content, limit))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, err
+	}
+
+	var extra [1]byte
+	if n, _ := content.Read(extra[:]); n > 0 {
+		if err := os.Truncate(r.path(id), offset); err != nil {
+			return written, err
+		}
+		return 0, fmt.Errorf("%w: content exceeds the upload length", entity.ErrInvalidAttachment)
+	}
+	return written, nil
+}
+
+// Open opens the staging file of a session
+func (r *UploadSessionRepositoryImpl) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	r.mu.Lock()
+	_, ok := r.sessions[id]
+	r.mu.Unlock()
+	if !ok {
+		return nil, entity.ErrUploadNotFound
+	}
+	return os.Open(r.path(id))
+}
+
+// Delete removes a session that is not being written
+func (r *UploadSessionRepositoryImpl) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	staged, ok := r.sessions[id]
+	if !ok {
+		return entity.ErrUploadNotFound
+	}
+	if staged.busy {
+		return entity.ErrUploadBusy
+	}
+	delete(r.sessions, id)
+	return os.Remove(r.path(id))
+}
+
+// DeleteExpired removes expired sessions that are not being written
+func (r *UploadSessionRepositoryImpl) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := 0
+	for id, staged := range r.sessions {
+		if staged.busy || !staged.session.IsExpired(now) {
+			continue
+		}
+		delete(r.sessions, id)
+		if err := os.Remove(r.path(id)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// path returns the staging file of a session
+func (r *UploadSessionRepositoryImpl) path(id string) string {
+	return filepath.Join(r.dir, id)
+}
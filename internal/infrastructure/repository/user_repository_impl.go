@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"math/rand"
+	"sync"
 
 	"frontend-challenge/internal/domain/entity"
 	"frontend-challenge/internal/domain/repository"
@@ -13,11 +14,15 @@ import (
 // UserRepositoryImpl implements UserRepository
 type UserRepositoryImpl struct {
 	// In a real implementation, here we would have a database connection
+	mu    sync.Mutex
+	users map[string]*entity.User
 }
 
 // NewUserRepositoryImpl creates a new instance of UserRepositoryImpl
 func NewUserRepositoryImpl() repository.UserRepository {
-	return &UserRepositoryImpl{}
+	return &UserRepositoryImpl{
+		users: make(map[string]*entity.User),
+	}
 }
 
 // GetAll gets all users (simulated)
@@ -36,25 +41,46 @@ func (r *UserRepositoryImpl) GetAll(ctx context.Context) ([]*entity.User, error)
 
 // GetByID gets a user by its ID (simulated)
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	if id == "" {
+		return nil, entity.ErrInvalidUserID
+	}
+
 	// In a real implementation, we would search in the database
-	// For now, we generate a random user
-	return entity.NewUser(gofakeit.UUID(), gofakeit.Name()), nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, entity.ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
 }
 
 // Create creates a new user (simulated)
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *entity.User) error {
 	// In a real implementation, we would insert into the database
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *user
+	r.users[user.ID] = &copied
 	return nil
 }
 
 // Update updates an existing user (simulated)
 func (r *UserRepositoryImpl) Update(ctx context.Context, user *entity.User) error {
 	// In a real implementation, we would update in the database
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *user
+	r.users[user.ID] = &copied
 	return nil
 }
 
 // Delete deletes a user (simulated)
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id string) error {
 	// In a real implementation, we would delete from the database
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
 	return nil
 }
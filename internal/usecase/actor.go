@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// actorKey is the context key of the user performing a request
+type actorKey struct{}
+
+// WithActor returns a context carrying the user performing the request
+func WithActor(ctx context.Context, user entity.User) context.Context {
+	return context.WithValue(ctx, actorKey{}, user)
+}
+
+// ActorFromContext returns the user performing the request, if known
+func ActorFromContext(ctx context.Context) (entity.User, bool) {
+	user, ok := ctx.Value(actorKey{}).(entity.User)
+	return user, ok
+}
+
+// internalKey is the context key marking work the server does on its own
+type internalKey struct{}
+
+// Internal returns a context for work the server does on its own, like its
+// background jobs, which no user performs and which is not authorized.
+// Calls without an actor that are not marked this way are refused.
+func Internal(ctx context.Context) context.Context {
+	return context.WithValue(context.WithValue(ctx, actorKey{}, nil), internalKey{}, true)
+}
+
+// isInternal reports whether the context was marked by Internal
+func isInternal(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalKey{}).(bool)
+	return internal
+}
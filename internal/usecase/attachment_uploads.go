@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// CreateUpload starts a resumable upload of an attachment of the given
+// length. An empty attachment is complete right away, in which case the
+// updated document is returned as well.
+func (u *AttachmentUsecase) CreateUpload(ctx context.Context, documentID, name, mimeType string, length int64) (*entity.UploadSession, *entity.Document, error) {
+	if _, err := u.documents.getAuthorized(ctx, documentID, entity.PermissionWrite); err != nil {
+		return nil, nil, err
+	}
+	if err := entity.ValidateAttachmentName(name); err != nil {
+		return nil, nil, err
+	}
+	if length < 0 {
+		return nil, nil, entity.ErrInvalidAttachment
+	}
+	if length > u.maxSize {
+		return nil, nil, entity.ErrAttachmentTooLarge
+	}
+
+	author, _ := ActorFromContext(ctx)
+	session := entity.NewUploadSession(rand.Text(), documentID, name, mimeType, length, author, u.uploadExpiry)
+	if err := u.uploads.Create(ctx, session); err != nil {
+		return nil, nil, err
+	}
+	if !session.IsComplete() {
+		return session, nil, nil
+	}
+	document, err := u.completeUpload(ctx, session)
+	return session, document, err
+}
+
+// GetUpload returns an upload of a document that can still be resumed
+func (u *AttachmentUsecase) GetUpload(ctx context.Context, documentID, uploadID string) (*entity.UploadSession, error) {
+	session, err := u.getSession(ctx, documentID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.IsExpired(time.Now()) {
+		return nil, entity.ErrUploadExpired
+	}
+	return session, nil
+}
+
+// AppendUpload adds the next chunk of an upload, which must start at
+// offset. Once the last chunk arrives the content is attached to the
+// document, which is returned as well. The session is returned even when
+// the chunk is only partially written, so clients learn where to resume.
+func (u *AttachmentUsecase) AppendUpload(ctx context.Context, documentID, uploadID string, offset int64, chunk io.Reader) (*entity.UploadSession, *entity.Document, error) {
+	session, err := u.GetUpload(ctx, documentID, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A complete session is still around if attaching it failed; an empty
+	// chunk at the end retries that
+	if !session.IsComplete() {
+		session, err = u.uploads.Append(ctx, uploadID, offset, chunk)
+		if err != nil || !session.IsComplete() {
+			return session, nil, err
+		}
+	} else if offset != session.Offset {
+		return session, nil, entity.ErrUploadOffsetMismatch
+	} else if n, _ := chunk.Read(make([]byte, 1)); n > 0 {
+		return session, nil, fmt.Errorf("%w: the upload is already complete", entity.ErrInvalidAttachment)
+	}
+
+	document, err := u.completeUpload(ctx, session)
+	return session, document, err
+}
+
+// TerminateUpload abandons an upload and discards its content
+func (u *AttachmentUsecase) TerminateUpload(ctx context.Context, documentID, uploadID string) error {
+	if _, err := u.getSession(ctx, documentID, uploadID); err != nil {
+		return err
+	}
+	return u.uploads.Delete(ctx, uploadID)
+}
+
+// getSession returns an upload of a document started by the user making
+// the request, who must still be allowed to write the document. Uploads of
+// other users are reported as not found.
+func (u *AttachmentUsecase) getSession(ctx context.Context, documentID, uploadID string) (*entity.UploadSession, error) {
+	if _, err := u.documents.getAuthorized(ctx, documentID, entity.PermissionWrite); err != nil {
+		return nil, err
+	}
+	session, err := u.uploads.GetByID(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	actor, _ := ActorFromContext(ctx)
+	if session.DocumentID != documentID || !session.IsStartedBy(actor) {
+		return nil, entity.ErrUploadNotFound
+	}
+	return session, nil
+}
+
+// PurgeExpiredUploads discards the uploads that were not finished in time
+func (u *AttachmentUsecase) PurgeExpiredUploads(ctx context.Context) (int, error) {
+	return u.uploads.DeleteExpired(ctx, time.Now())
+}
+
+// completeUpload moves the staged content of a finished upload into the
+// blob store and attaches it to the document
+func (u *AttachmentUsecase) completeUpload(ctx context.Context, session *entity.UploadSession) (*entity.Document, error) {
+	content, err := u.uploads.Open(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := u.store(ctx, &Upload{
+		Name:     session.Name,
+		MimeType: session.MimeType,
+		Content:  content,
+	})
+	content.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := u.Attach(ctx, session.DocumentID, UpdatePrecondition{}, attachment)
+	if err != nil {
+		// The staged content is kept, so committing again stores it again
+		return nil, err
+	}
+	if err := u.uploads.Delete(ctx, session.ID); err != nil && !errors.Is(err, entity.ErrUploadNotFound) {
+		return nil, err
+	}
+	return document, nil
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// sniffLen is how much content is inspected to detect a MIME type
+const sniffLen = 512
+
+// Upload is a file sent by a client
+type Upload struct {
+	Name string
+	// MimeType is detected from the content when empty or generic
+	MimeType string
+	Content  io.Reader
+}
+
+// AttachmentUsecase handles the content of document attachments
+type AttachmentUsecase struct {
+	documents    *DocumentUsecase
+	blobs        repository.BlobStore
+	uploads      repository.UploadSessionRepository
+	maxSize      int64
+	uploadExpiry time.Duration
+}
+
+// NewAttachmentUsecase creates a new instance of AttachmentUsecase.
+// maxSize bounds the size of a single attachment and uploadExpiry how long
+// a resumable upload may take.
+func NewAttachmentUsecase(
+	documents *DocumentUsecase,
+	blobs repository.BlobStore,
+	uploads repository.UploadSessionRepository,
+	maxSize int64,
+	uploadExpiry time.Duration,
+) *AttachmentUsecase {
+	return &AttachmentUsecase{
+		documents:    documents,
+		blobs:        blobs,
+		uploads:      uploads,
+		maxSize:      maxSize,
+		uploadExpiry: uploadExpiry,
+	}
+}
+
+// MaxSize returns the largest attachment accepted, in bytes
+func (u *AttachmentUsecase) MaxSize() int64 {
+	return u.maxSize
+}
+
+// UploadAttachments stores the files returned by next until it returns
+// io.EOF and attaches them to the document in a single revision. An upload
+// replaces an attachment with the same name.
+func (u *AttachmentUsecase) UploadAttachments(ctx context.Context, id string, pre UpdatePrecondition, next func() (*Upload, error)) (*entity.Document, []entity.Attachment, error) {
+	// Fail before reading the content if the upload would be rejected anyway
+	current, err := u.documents.getAuthorized(ctx, id, entity.PermissionWrite)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := pre.check(current); err != nil {
+		return nil, nil, err
+	}
+
+	// Content stored by a request that fails is left to SweepBlobs
+	var attachments []entity.Attachment
+	for {
+		upload, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		attachment, err := u.store(ctx, upload)
+		if err != nil {
+			return nil, nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	if len(attachments) == 0 {
+		return nil, nil, entity.ErrInvalidAttachment
+	}
+
+	document, err := u.Attach(ctx, id, pre, attachments...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, attachments, nil
+}
+
+// Attach adds stored attachments to a document
+func (u *AttachmentUsecase) Attach(ctx context.Context, id string, pre UpdatePrecondition, attachments ...entity.Attachment) (*entity.Document, error) {
+	current, err := u.documents.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := pre.check(current); err != nil {
+		return nil, err
+	}
+	return u.documents.save(ctx, current, entity.RevisionUpdated, func(d *entity.Document) error {
+		for _, attachment := range attachments {
+			d.PutAttachment(attachment)
+		}
+		return nil
+	})
+}
+
+// OpenAttachment returns an attachment of a document with its content.
+// Attachments that only have a name have no content to return.
+func (u *AttachmentUsecase) OpenAttachment(ctx context.Context, id, name string) (entity.Attachment, io.ReadSeekCloser, error) {
+	document, err := u.documents.GetDocumentByID(ctx, id)
+	if err != nil {
+		return entity.Attachment{}, nil, err
+	}
+	attachment, ok := document.Attachment(name)
+	if !ok || !attachment.HasContent() {
+		return entity.Attachment{}, nil, entity.ErrAttachmentNotFound
+	}
+	content, err := u.blobs.Open(ctx, attachment.Checksum)
+	if err != nil {
+		return entity.Attachment{}, nil, err
+	}
+	return attachment, content, nil
+}
+
+// store writes an upload to the blob store
+func (u *AttachmentUsecase) store(ctx context.Context, upload *Upload) (entity.Attachment, error) {
+	name := strings.TrimSpace(path.Base(strings.ReplaceAll(upload.Name, `\`, "/")))
+	if err := entity.ValidateAttachmentName(name); err != nil {
+		return entity.Attachment{}, err
+	}
+
+	content := bufio.NewReaderSize(upload.Content, sniffLen)
+	mimeType := upload.MimeType
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		// Peek reports short content with an error; what it returns is enough
+		head, _ := content.Peek(sniffLen)
+		mimeType = http.DetectContentType(head)
+	}
+
+	blob, err := u.blobs.Put(ctx, content, u.maxSize)
+	if err != nil {
+		return entity.Attachment{}, err
+	}
+	return entity.Attachment{
+		Name:     name,
+		Size:     blob.Size,
+		MimeType: mimeType,
+		Checksum: blob.Checksum,
+	}, nil
+}
+
+// SweepBlobs deletes the stored content that no document, in the trash or
+// not, nor any of their revisions refers to. Content stored within grace is
+// kept, as uploads store content before attaching it.
+func (u *AttachmentUsecase) SweepBlobs(ctx context.Context, grace time.Duration) (int, error) {
+	// The cutoff comes first: content attached after the references are
+	// collected was stored after it
+	cutoff := time.Now().Add(-grace)
+	referenced, err := u.documents.referencedContent(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return u.blobs.Sweep(ctx, cutoff, func(checksum string) bool {
+		return referenced[checksum]
+	})
+}
@@ -0,0 +1,100 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/infrastructure/repository"
+	"frontend-challenge/internal/usecase"
+)
+
+// newAttachmentUsecase returns an AttachmentUsecase storing content in a
+// temporary directory, over the documents of newDocumentUsecase
+func newAttachmentUsecase(t *testing.T) (*usecase.AttachmentUsecase, string) {
+	t.Helper()
+	documents, documentID := newDocumentUsecase(t)
+	blobs, err := repository.NewBlobStoreImpl(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewBlobStoreImpl: %v", err)
+	}
+	uploads, err := repository.NewUploadSessionRepositoryImpl(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewUploadSessionRepositoryImpl: %v", err)
+	}
+	return usecase.NewAttachmentUsecase(documents, blobs, uploads, 1<<20, time.Hour), documentID
+}
+
+// uploads returns a next function for UploadAttachments that returns the
+// files and then err
+func uploads(err error, files map[string]string) func() (*usecase.Upload, error) {
+	var pending []*usecase.Upload
+	for name, content := range files {
+		pending = append(pending, &usecase.Upload{Name: name, Content: strings.NewReader(content)})
+	}
+	return func() (*usecase.Upload, error) {
+		if len(pending) == 0 {
+			return nil, err
+		}
+		upload := pending[0]
+		pending = pending[1:]
+		return upload, nil
+	}
+}
+
+func TestSweepBlobsKeepsReferencedContent(t *testing.T) {
+	attachments, documentID := newAttachmentUsecase(t)
+	ctx := usecase.WithActor(context.Background(), ann)
+
+	_, _, err := attachments.UploadAttachments(ctx, documentID, usecase.UpdatePrecondition{}, uploads(io.EOF, map[string]string{"kept.txt": "kept"}))
+	if err != nil {
+		t.Fatalf("UploadAttachments: %v", err)
+	}
+	// A request failing after storing content leaves it behind, even when it
+	// is the content of another attachment
+	broken := errors.New("connection reset")
+	_, _, err = attachments.UploadAttachments(ctx, documentID, usecase.UpdatePrecondition{}, uploads(broken, map[string]string{"copy.txt": "kept", "orphan.txt": "orphan"}))
+	if !errors.Is(err, broken) {
+		t.Fatalf("UploadAttachments: got %v, want %v", err, broken)
+	}
+
+	deleted, err := attachments.SweepBlobs(usecase.Internal(context.Background()), time.Hour)
+	if err != nil {
+		t.Fatalf("SweepBlobs: %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("deleted %d blobs within the grace period, want 0", deleted)
+	}
+
+	// A negative grace puts the cutoff in the future, past everything stored
+	deleted, err = attachments.SweepBlobs(usecase.Internal(context.Background()), -time.Minute)
+	if err != nil {
+		t.Fatalf("SweepBlobs: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted %d blobs, want only the orphan", deleted)
+	}
+	_, content, err := attachments.OpenAttachment(ctx, documentID, "kept.txt")
+	if err != nil {
+		t.Fatalf("OpenAttachment: %v", err)
+	}
+	content.Close()
+}
+
+func TestPatchRejectsInvalidAttachmentNames(t *testing.T) {
+	documents, documentID := newDocumentUsecase(t)
+	ctx := usecase.WithActor(context.Background(), ann)
+	for _, name := range []string{"", "..", "a/b", `a\b`, `say "hi".txt`, "line\nbreak"} {
+		_, err := documents.PatchDocument(ctx, documentID, usecase.UpdatePrecondition{}, func(d *entity.Document) error {
+			d.Attachments = append(d.Attachments, entity.NewAttachment(name))
+			return nil
+		})
+		if !errors.Is(err, entity.ErrInvalidAttachment) {
+			t.Errorf("attachment %q: got %v, want %v", name, err, entity.ErrInvalidAttachment)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// cursor is the decoded form of the opaque pagination token handed to clients
+type cursor struct {
+	Key    repository.SortKey `json:"k"`
+	Before bool               `json:"b,omitempty"`
+	// Sort is the listing order the key belongs to
+	Sort string `json:"s,omitempty"`
+}
+
+// encodeCursor turns a sort key into an opaque token
+func encodeCursor(key repository.SortKey, before bool, sort string) string {
+	data, _ := json.Marshal(cursor{Key: key, Before: before, Sort: sort})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token produced by encodeCursor
+func decodeCursor(token string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Key.ID == "" {
+		return nil, entity.ErrInvalidCursor
+	}
+	return &c, nil
+}
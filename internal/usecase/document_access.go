@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// AccessControl is who may access a document besides its contributors
+type AccessControl struct {
+	OwnerID string         `json:"ownerId"`
+	ACL     []entity.Grant `json:"acl"`
+}
+
+// GetAccess returns the owner and grants of a document
+func (u *DocumentUsecase) GetAccess(ctx context.Context, id string) (*AccessControl, error) {
+	document, err := u.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return accessOf(document), nil
+}
+
+// SetAccess replaces the grants of a document and, when given, its owner.
+// Both take admin permission, which documents without an owner only grant
+// through their contributors and grants.
+func (u *DocumentUsecase) SetAccess(ctx context.Context, id string, access AccessControl, pre UpdatePrecondition) (*entity.Document, *AccessControl, error) {
+	document, err := u.PatchDocument(ctx, id, pre, func(d *entity.Document) error {
+		if access.OwnerID != "" {
+			d.OwnerID = access.OwnerID
+		}
+		d.ACL = access.ACL
+		if len(d.ACL) == 0 {
+			d.ACL = nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, accessOf(document), nil
+}
+
+// accessOf extracts the access control of a document
+func accessOf(document *entity.Document) *AccessControl {
+	access := &AccessControl{OwnerID: document.OwnerID, ACL: document.ACL}
+	if access.ACL == nil {
+		access.ACL = []entity.Grant{}
+	}
+	return access
+}
+
+// principal returns the caller in the context with its groups. ok is false
+// for work the server does on its own (see Internal), which is not
+// restricted; other calls without a caller fail with ErrUnauthenticated.
+func (u *DocumentUsecase) principal(ctx context.Context) (entity.Principal, bool, error) {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		if isInternal(ctx) {
+			return entity.Principal{}, false, nil
+		}
+		return entity.Principal{}, false, entity.ErrUnauthenticated
+	}
+	groups, err := u.groupRepo.GetGroupsByUserID(ctx, actor.ID)
+	if err != nil {
+		return entity.Principal{}, false, err
+	}
+	return entity.Principal{UserID: actor.ID, Groups: groups}, true, nil
+}
+
+// readableBy returns the principal listings must be restricted to, or nil
+// for work the server does on its own
+func (u *DocumentUsecase) readableBy(ctx context.Context) (*entity.Principal, error) {
+	p, ok, err := u.principal(ctx)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// authorize checks that the caller holds a permission on the document
+func (u *DocumentUsecase) authorize(ctx context.Context, document *entity.Document, permission string) error {
+	p, ok, err := u.principal(ctx)
+	if err != nil || !ok {
+		return err
+	}
+	if !document.Allows(p, permission) {
+		return &entity.AccessDeniedError{UserID: p.UserID, DocumentID: document.ID, Permission: permission}
+	}
+	return nil
+}
+
+// CanRead reports whether a user may read a document, in the trash or not,
+// e.g. to be told about changes to it
+func (u *DocumentUsecase) CanRead(ctx context.Context, userID, documentID string) bool {
+	document, err := u.documentRepo.GetByID(ctx, documentID)
+	if err != nil {
+		return false
+	}
+	groups, err := u.groupRepo.GetGroupsByUserID(ctx, userID)
+	if err != nil {
+		return false
+	}
+	return document.Allows(entity.Principal{UserID: userID, Groups: groups}, entity.PermissionRead)
+}
+
+// getAuthorized retrieves a document outside the trash on which the caller
+// holds a permission
+func (u *DocumentUsecase) getAuthorized(ctx context.Context, id, permission string) (*entity.Document, error) {
+	if id == "" {
+		return nil, entity.ErrInvalidDocumentID
+	}
+	document, err := u.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.IsDeleted() {
+		return nil, entity.ErrDocumentNotFound
+	}
+	if err := u.authorize(ctx, document, permission); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// requiredPermission is what it takes to store updated in place of current
+// with the given revision action. Trashing and restoring documents and
+// changing who has access to them takes admin, anything else write.
+func requiredPermission(action string, current, updated *entity.Document) string {
+	switch {
+	case action == entity.RevisionDeleted || action == entity.RevisionRestored:
+		return entity.PermissionAdmin
+	case current.OwnerID != updated.OwnerID,
+		!slices.Equal(current.ACL, updated.ACL),
+		entity.DiffDocuments(current, updated).Contributors != nil:
+		return entity.PermissionAdmin
+	}
+	return entity.PermissionWrite
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// MaxBatchSize is the maximum number of operations in one batch
+const MaxBatchSize = 1000
+
+// BatchAction is the kind of change a batch operation makes
+type BatchAction string
+
+// Supported batch actions
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// BatchOperation is one change in a batch
+type BatchOperation struct {
+	Action BatchAction
+	// ID selects the document to update or delete
+	ID string
+	// Document is the new document for create and update
+	Document     *entity.Document
+	Precondition UpdatePrecondition
+}
+
+// BatchResult is the outcome of one batch operation
+type BatchResult struct {
+	// Document is the document as stored after the operation
+	Document *entity.Document
+	Err      error
+	// RolledBack is set when the operation succeeded but was undone
+	// because another operation of an all-or-nothing batch failed
+	RolledBack bool
+	// RollbackErr is set instead when undoing the operation failed, for
+	// instance because the document was written again meanwhile; the
+	// change is kept
+	RollbackErr error
+}
+
+// undoFunc reverts a successful batch operation
+type undoFunc func(ctx context.Context) error
+
+// ExecuteBatch runs the operations in order. When atomic is set, the first
+// failure stops the batch and every operation already applied is undone;
+// operations that could not be undone report a RollbackErr. The returned
+// results line up with the operations.
+func (u *DocumentUsecase) ExecuteBatch(ctx context.Context, operations []BatchOperation, atomic bool) ([]BatchResult, error) {
+	if len(operations) == 0 || len(operations) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: a batch needs between 1 and %d operations", entity.ErrInvalidBatchOperation, MaxBatchSize)
+	}
+	for i, op := range operations {
+		if err := op.validate(); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	results := make([]BatchResult, len(operations))
+	undo := make([]undoFunc, 0, len(operations))
+	for i, op := range operations {
+		document, revert, err := u.executeBatchOperation(ctx, op)
+		results[i] = BatchResult{Document: document, Err: err}
+		if err == nil {
+			undo = append(undo, revert)
+			continue
+		}
+		if atomic {
+			// undo holds one step per operation before i, which all succeeded
+			for j := len(undo) - 1; j >= 0; j-- {
+				// Best effort: a concurrent write wins over the rollback
+				if err := undo[j](ctx); err != nil {
+					results[j].RollbackErr = err
+					continue
+				}
+				results[j].RolledBack = true
+			}
+			for j := i + 1; j < len(operations); j++ {
+				results[j].Err = fmt.Errorf("%w: not attempted after operation %d failed", entity.ErrBatchAborted, i)
+			}
+			return results, nil
+		}
+	}
+	return results, nil
+}
+
+// validate checks that an operation carries what its action needs
+func (op BatchOperation) validate() error {
+	switch op.Action {
+	case BatchCreate:
+		if op.Document == nil {
+			return fmt.Errorf("%w: create needs a document", entity.ErrInvalidBatchOperation)
+		}
+	case BatchUpdate:
+		if op.ID == "" || op.Document == nil {
+			return fmt.Errorf("%w: update needs an id and a document", entity.ErrInvalidBatchOperation)
+		}
+		if op.Document.ID != "" && op.Document.ID != op.ID {
+			return fmt.Errorf("%w: document id does not match the operation id", entity.ErrInvalidBatchOperation)
+		}
+	case BatchDelete:
+		if op.ID == "" {
+			return fmt.Errorf("%w: delete needs an id", entity.ErrInvalidBatchOperation)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", entity.ErrInvalidBatchOperation, op.Action)
+	}
+	return nil
+}
+
+// executeBatchOperation applies one operation and returns how to revert it
+func (u *DocumentUsecase) executeBatchOperation(ctx context.Context, op BatchOperation) (*entity.Document, undoFunc, error) {
+	switch op.Action {
+	case BatchCreate:
+		if err := u.CreateDocument(ctx, op.Document); err != nil {
+			return nil, nil, err
+		}
+		return op.Document, func(ctx context.Context) error {
+			// The document never existed as far as anyone else is concerned
+			if err := u.documentRepo.Delete(ctx, op.Document.ID); err != nil {
+				return err
+			}
+			return u.revisionRepo.DeleteByDocumentID(ctx, op.Document.ID)
+		}, nil
+	case BatchUpdate:
+		previous, err := u.GetDocumentByID(ctx, op.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		document := op.Document.Clone()
+		document.ID = op.ID
+		updated, err := u.UpdateDocument(ctx, document, op.Precondition)
+		if err != nil {
+			return nil, nil, err
+		}
+		return updated, u.restoreSnapshot(previous, updated), nil
+	default:
+		previous, err := u.GetDocumentByID(ctx, op.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		deleted, err := u.DeleteDocument(ctx, op.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return deleted, u.restoreSnapshot(previous, deleted), nil
+	}
+}
+
+// restoreSnapshot returns an undo step that puts previous back, unless the
+// document changed again after the batch wrote current
+func (u *DocumentUsecase) restoreSnapshot(previous, current *entity.Document) undoFunc {
+	return func(ctx context.Context) error {
+		return u.commitUpdate(ctx, current, previous, entity.RevisionReverted)
+	}
+}
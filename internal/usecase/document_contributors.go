@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// GetContributors returns the contributors of a document
+func (u *DocumentUsecase) GetContributors(ctx context.Context, id string) ([]entity.Contributor, error) {
+	document, err := u.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.Contributors == nil {
+		return []entity.Contributor{}, nil
+	}
+	return document.Contributors, nil
+}
+
+// AddContributor resolves a user and adds it to the document with the
+// given role, editor when empty
+func (u *DocumentUsecase) AddContributor(ctx context.Context, id, userID, role string, pre UpdatePrecondition) (*entity.Document, entity.Contributor, error) {
+	if role == "" {
+		role = entity.RoleEditor
+	}
+	if err := entity.ValidateRole(role); err != nil {
+		return nil, entity.Contributor{}, err
+	}
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, entity.Contributor{}, err
+	}
+
+	document, err := u.PatchDocument(ctx, id, pre, func(d *entity.Document) error {
+		return d.AddContributor(*user, role)
+	})
+	if err != nil {
+		return nil, entity.Contributor{}, err
+	}
+	return document, entity.NewContributor(*user, role), nil
+}
+
+// RemoveContributor removes a user from the document and returns the
+// removed contributor
+func (u *DocumentUsecase) RemoveContributor(ctx context.Context, id, userID string, pre UpdatePrecondition) (*entity.Document, entity.Contributor, error) {
+	var removed entity.Contributor
+	document, err := u.PatchDocument(ctx, id, pre, func(d *entity.Document) error {
+		var err error
+		removed, err = d.RemoveContributor(userID)
+		return err
+	})
+	if err != nil {
+		return nil, entity.Contributor{}, err
+	}
+	return document, removed, nil
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// GetLock returns the current lock of a document
+func (u *DocumentUsecase) GetLock(ctx context.Context, id string) (*entity.Lock, error) {
+	if _, err := u.getAuthorized(ctx, id, entity.PermissionRead); err != nil {
+		return nil, err
+	}
+	lock, err := u.lockRepo.GetByDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if lock.Expired(time.Now()) {
+		return nil, entity.ErrLockNotFound
+	}
+	return lock, nil
+}
+
+// AcquireLock locks a document for the caller, who needs write permission,
+// for the lease TTL. Acquiring a lock the caller already holds renews its
+// lease, which is reported by renewed.
+func (u *DocumentUsecase) AcquireLock(ctx context.Context, id string) (document *entity.Document, lock *entity.Lock, renewed bool, err error) {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return nil, nil, false, entity.ErrUnauthenticated
+	}
+	document, err = u.getAuthorized(ctx, id, entity.PermissionWrite)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	now := time.Now()
+	lock, err = u.lockRepo.Update(ctx, id, func(current *entity.Lock) (*entity.Lock, error) {
+		acquiredAt := now
+		if current != nil && !current.Expired(now) {
+			if current.UserID != actor.ID {
+				return nil, &entity.LockedError{Lock: current}
+			}
+			acquiredAt = current.AcquiredAt
+			renewed = true
+		}
+		return &entity.Lock{
+			DocumentID: id,
+			UserID:     actor.ID,
+			UserName:   actor.Name,
+			AcquiredAt: acquiredAt,
+			ExpiresAt:  now.Add(u.lockTTL),
+		}, nil
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return document, lock, renewed, nil
+}
+
+// ReleaseLock unlocks a document. Only the holder may release its lock,
+// unless force is set and the caller has admin permission on the document.
+func (u *DocumentUsecase) ReleaseLock(ctx context.Context, id string, force bool) (*entity.Document, *entity.Lock, error) {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return nil, nil, entity.ErrUnauthenticated
+	}
+	permission := entity.PermissionWrite
+	if force {
+		permission = entity.PermissionAdmin
+	}
+	document, err := u.getAuthorized(ctx, id, permission)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	var released *entity.Lock
+	_, err = u.lockRepo.Update(ctx, id, func(current *entity.Lock) (*entity.Lock, error) {
+		if current == nil || current.Expired(now) {
+			return nil, entity.ErrLockNotFound
+		}
+		if current.UserID != actor.ID && !force {
+			return nil, &entity.LockedError{Lock: current}
+		}
+		released = current
+		return nil, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, released, nil
+}
+
+// ExpireLocks removes the locks whose lease ran out and returns them
+func (u *DocumentUsecase) ExpireLocks(ctx context.Context) ([]*entity.Lock, error) {
+	return u.lockRepo.DeleteExpired(ctx, time.Now())
+}
+
+// checkLock rejects changes to a document locked by somebody other than the
+// caller. Calls without a caller are not held back by locks.
+func (u *DocumentUsecase) checkLock(ctx context.Context, id string) error {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	lock, err := u.lockRepo.GetByDocumentID(ctx, id)
+	if errors.Is(err, entity.ErrLockNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if lock.Expired(time.Now()) || lock.UserID == actor.ID {
+		return nil
+	}
+	return &entity.LockedError{Lock: lock}
+}
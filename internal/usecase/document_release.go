@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/pkg/semver"
+)
+
+// releaseAttempts bounds the retries of a release that lost a race with a
+// concurrent update
+const releaseAttempts = 3
+
+// ReleaseDocument bumps the document version to the next major, minor or
+// patch release and records it in the history. The next version is
+// computed from the stored one, so concurrent releases never hand out the
+// same version twice.
+func (u *DocumentUsecase) ReleaseDocument(ctx context.Context, id, bump string, pre UpdatePrecondition) (*entity.Document, error) {
+	switch bump {
+	case semver.Major, semver.Minor, semver.Patch:
+	default:
+		return nil, entity.ErrInvalidVersionBump
+	}
+
+	for attempt := 1; ; attempt++ {
+		released, err := u.release(ctx, id, bump, pre)
+
+		// Without a precondition the client did not ask for a specific
+		// version to be bumped, so a lost race is simply retried
+		if errors.Is(err, entity.ErrDocumentVersionConflict) && pre.empty() && attempt < releaseAttempts {
+			continue
+		}
+		return released, err
+	}
+}
+
+// release makes a single attempt at bumping the stored version
+func (u *DocumentUsecase) release(ctx context.Context, id, bump string, pre UpdatePrecondition) (*entity.Document, error) {
+	current, err := u.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := pre.check(current); err != nil {
+		return nil, err
+	}
+	return u.save(ctx, current, entity.RevisionReleased, func(d *entity.Document) error {
+		current, err := semver.Parse(d.Version)
+		if err != nil {
+			return entity.ErrInvalidDocumentVersion
+		}
+		next, err := current.Bump(bump)
+		if err != nil {
+			return entity.ErrInvalidVersionBump
+		}
+		d.Version = next.String()
+		return nil
+	})
+}
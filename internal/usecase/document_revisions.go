@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+
+	"frontend-challenge/internal/domain/entity"
+)
+
+// RevisionDiff is the difference between two revisions of a document
+type RevisionDiff struct {
+	From    int                 `json:"from"`
+	To      int                 `json:"to"`
+	Changes entity.DocumentDiff `json:"changes"`
+}
+
+// GetRevisions returns the history of a document, oldest first.
+// Trashed documents keep their history.
+func (u *DocumentUsecase) GetRevisions(ctx context.Context, id string) ([]*entity.Revision, error) {
+	document, err := u.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.authorize(ctx, document, entity.PermissionRead); err != nil {
+		return nil, err
+	}
+	return u.revisionRepo.GetByDocumentID(ctx, id)
+}
+
+// GetRevision returns one revision of a document
+func (u *DocumentUsecase) GetRevision(ctx context.Context, id string, number int) (*entity.Revision, error) {
+	document, err := u.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.authorize(ctx, document, entity.PermissionRead); err != nil {
+		return nil, err
+	}
+	return u.revisionRepo.Get(ctx, id, number)
+}
+
+// DiffRevisions compares two revisions of a document. A zero to selects the
+// latest revision and a zero from the one before to.
+func (u *DocumentUsecase) DiffRevisions(ctx context.Context, id string, from, to int) (*RevisionDiff, error) {
+	if from < 0 || to < 0 {
+		return nil, entity.ErrInvalidRevision
+	}
+	history, err := u.GetRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, entity.ErrRevisionNotFound
+	}
+
+	if to == 0 {
+		to = len(history)
+	}
+	if from == 0 {
+		from = to - 1
+		if from == 0 {
+			// A single revision is compared with itself
+			from = to
+		}
+	}
+	if from > len(history) || to > len(history) {
+		return nil, entity.ErrRevisionNotFound
+	}
+
+	return &RevisionDiff{
+		From:    from,
+		To:      to,
+		Changes: entity.DiffDocuments(&history[from-1].Snapshot, &history[to-1].Snapshot),
+	}, nil
+}
+
+// RevertDocument restores the content of an earlier revision. The document
+// keeps its identity and its current version, which only ever moves
+// forward, and the revert is recorded as a new revision.
+func (u *DocumentUsecase) RevertDocument(ctx context.Context, id string, number int, pre UpdatePrecondition) (*entity.Document, error) {
+	current, err := u.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := u.revisionRepo.Get(ctx, id, number)
+	if err != nil {
+		return nil, err
+	}
+	if err := pre.check(current); err != nil {
+		return nil, err
+	}
+
+	snapshot := revision.Snapshot.Clone()
+	return u.save(ctx, current, entity.RevisionReverted, func(d *entity.Document) error {
+		d.Title = snapshot.Title
+		d.Attachments = snapshot.Attachments
+		d.Contributors = snapshot.Contributors
+		d.Tags = snapshot.Tags
+		return nil
+	})
+}
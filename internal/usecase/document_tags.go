@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// GetTags returns every tag in use with the number of documents carrying
+// it, counting the documents the caller may read
+func (u *DocumentUsecase) GetTags(ctx context.Context) ([]repository.TagCount, error) {
+	readableBy, err := u.readableBy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return u.documentRepo.TagCounts(ctx, repository.DocumentFilter{ReadableBy: readableBy})
+}
+
+// GetDocumentTags returns the tags of a document
+func (u *DocumentUsecase) GetDocumentTags(ctx context.Context, id string) ([]string, error) {
+	document, err := u.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document.Tags == nil {
+		return []string{}, nil
+	}
+	return document.Tags, nil
+}
+
+// AddTags adds tags to a document; tags it already has are ignored
+func (u *DocumentUsecase) AddTags(ctx context.Context, id string, tags []string, pre UpdatePrecondition) (*entity.Document, error) {
+	if _, err := entity.NormalizeTags(tags); err != nil {
+		return nil, err
+	}
+	return u.PatchDocument(ctx, id, pre, func(d *entity.Document) error {
+		return d.AddTags(tags...)
+	})
+}
+
+// RemoveTag removes a tag from a document
+func (u *DocumentUsecase) RemoveTag(ctx context.Context, id, tag string, pre UpdatePrecondition) (*entity.Document, error) {
+	return u.PatchDocument(ctx, id, pre, func(d *entity.Document) error {
+		return d.RemoveTag(tag)
+	})
+}
+
+// normalizeTags puts the tags of a document in canonical form before it
+// is validated and stored
+func normalizeTags(document *entity.Document) error {
+	tags, err := entity.NormalizeTags(document.Tags)
+	if err != nil {
+		return err
+	}
+	document.Tags = tags
+	return nil
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// MaxImportErrors bounds the errors listed in an import report; further
+// errors are only counted
+const MaxImportErrors = 1000
+
+// What an import does with documents whose ID already exists
+const (
+	ImportConflictError   = "error"
+	ImportConflictSkip    = "skip"
+	ImportConflictReplace = "replace"
+)
+
+// ImportOptions controls an import
+type ImportOptions struct {
+	// DryRun checks every record as a real import would, without storing
+	// anything
+	DryRun bool
+	// OnConflict is one of the ImportConflict values, error when empty
+	OnConflict string
+}
+
+// ImportRecord is a document read from an import, or the reason it could
+// not be read
+type ImportRecord struct {
+	// Line is where the record starts in the input, counting from 1
+	Line     int
+	Document *entity.Document
+	Err      error
+}
+
+// ImportError reports a record that was not imported
+type ImportError struct {
+	Line  int    `json:"line"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportReport summarizes an import
+type ImportReport struct {
+	DryRun    bool          `json:"dryRun"`
+	Processed int           `json:"processed"`
+	Imported  int           `json:"imported"`
+	Skipped   int           `json:"skipped"`
+	Failed    int           `json:"failed"`
+	Errors    []ImportError `json:"errors"`
+	// ImportedIDs lists the documents stored by a real import
+	ImportedIDs []string `json:"-"`
+}
+
+// ExportDocuments calls fn for every document outside the trash the
+// caller may read, in ID order, streaming them from the repository
+func (u *DocumentUsecase) ExportDocuments(ctx context.Context, fn func(*entity.Document) error) error {
+	readableBy, err := u.readableBy(ctx)
+	if err != nil {
+		return err
+	}
+	return u.documentRepo.Iterate(ctx, func(document *entity.Document) error {
+		if readableBy != nil && !document.Allows(*readableBy, entity.PermissionRead) {
+			return nil
+		}
+		return fn(document)
+	})
+}
+
+// StreamDocuments calls fn for every document outside the trash that the
+// caller may read and that matches the filter, in ID order, streaming them
+// from the repository
+func (u *DocumentUsecase) StreamDocuments(ctx context.Context, filter repository.DocumentFilter, fn func(*entity.Document) error) error {
+	readableBy, err := u.readableBy(ctx)
+	if err != nil {
+		return err
+	}
+	filter.ReadableBy = readableBy
+	return u.documentRepo.IterateMatching(ctx, filter, fn)
+}
+
+// ImportDocuments stores the records returned by next until it returns
+// io.EOF. Each record is handled on its own: invalid ones are reported with
+// their line and do not stop the import. Records carrying timestamps keep
+// them. An error is only returned if next fails to read the input.
+func (u *DocumentUsecase) ImportDocuments(ctx context.Context, opts ImportOptions, next func() (*ImportRecord, error)) (*ImportReport, error) {
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = ImportConflictError
+	case ImportConflictError, ImportConflictSkip, ImportConflictReplace:
+	default:
+		return nil, fmt.Errorf("%w: onConflict must be error, skip or replace", entity.ErrInvalidImport)
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []ImportError{}}
+	// IDs seen so far, to catch duplicates within the input
+	seen := make(map[string]int)
+	for {
+		record, err := next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Processed++
+		skipped, err := u.importRecord(ctx, opts, record, seen)
+		switch {
+		case err != nil:
+			report.Failed++
+			if len(report.Errors) < MaxImportErrors {
+				importErr := ImportError{Line: record.Line, Error: err.Error()}
+				if record.Document != nil {
+					importErr.ID = record.Document.ID
+				}
+				report.Errors = append(report.Errors, importErr)
+			}
+		case skipped:
+			report.Skipped++
+		default:
+			report.Imported++
+			if !opts.DryRun {
+				report.ImportedIDs = append(report.ImportedIDs, record.Document.ID)
+			}
+		}
+	}
+}
+
+// importRecord checks and, unless it is a dry run, stores one record. A
+// dry run makes the same checks as a real import, access, locks and version
+// order included. It reports whether the record was skipped as an existing
+// document. New documents belong to the caller, whoever owned them in the
+// export, and keep their grants; replaced documents keep their owner and
+// grants, which only change through their access control.
+func (u *DocumentUsecase) importRecord(ctx context.Context, opts ImportOptions, record *ImportRecord, seen map[string]int) (bool, error) {
+	if record.Err != nil {
+		return false, record.Err
+	}
+	document := record.Document
+	if line, dup := seen[document.ID]; dup {
+		return false, fmt.Errorf("document %q already appears on line %d", document.ID, line)
+	}
+	if document.ID != "" {
+		seen[document.ID] = record.Line
+	}
+
+	now := time.Now()
+	if document.CreatedAt.IsZero() {
+		document.CreatedAt = now
+	}
+	if document.UpdatedAt.IsZero() {
+		document.UpdatedAt = document.CreatedAt
+	}
+	document.DeletedAt = nil
+
+	existing, err := u.documentRepo.GetByID(ctx, document.ID)
+	if err != nil && !errors.Is(err, entity.ErrDocumentNotFound) {
+		return false, err
+	}
+	if existing != nil {
+		switch opts.OnConflict {
+		case ImportConflictSkip:
+			return true, nil
+		case ImportConflictError:
+			return false, entity.ErrDocumentAlreadyExists
+		}
+		if existing.IsDeleted() {
+			return false, fmt.Errorf("%w: it is in the trash", entity.ErrDocumentAlreadyExists)
+		}
+	}
+
+	if existing == nil {
+		document.OwnerID = ""
+		if err := u.prepareCreate(ctx, document); err != nil {
+			return false, err
+		}
+		if err := u.checkImportedAttachments(ctx, &entity.Document{}, document); err != nil {
+			return false, err
+		}
+		if opts.DryRun {
+			return false, nil
+		}
+		return false, u.commitCreate(ctx, document)
+	}
+
+	updated, err := u.prepareUpdate(ctx, existing, entity.RevisionUpdated, func(d *entity.Document) error {
+		d.Title = document.Title
+		d.Version = document.Version
+		d.Attachments = document.Attachments
+		d.Contributors = document.Contributors
+		d.Tags = document.Tags
+		keepAttachmentContent(existing, d)
+		return u.checkImportedAttachments(ctx, existing, d)
+	})
+	if err != nil || opts.DryRun {
+		return false, err
+	}
+	return false, u.commitUpdate(ctx, existing, updated, entity.RevisionUpdated)
+}
+
+// checkImportedAttachments makes sure imported attachments only reference
+// content the caller can read already: content of the document, or of
+// another document the caller may read, so an export can be imported back
+// without handing out content of documents the caller cannot see
+func (u *DocumentUsecase) checkImportedAttachments(ctx context.Context, current, imported *entity.Document) error {
+	err := checkUploadedAttachments(current, imported)
+	if !errors.Is(err, entity.ErrAttachmentNotUploaded) {
+		return err
+	}
+	readable := &entity.Document{}
+	err = u.ExportDocuments(ctx, func(document *entity.Document) error {
+		readable.Attachments = append(readable.Attachments, document.Attachments...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return checkUploadedAttachments(readable, imported)
+}
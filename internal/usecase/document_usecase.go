@@ -2,22 +2,96 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"frontend-challenge/internal/domain/entity"
 	"frontend-challenge/internal/domain/repository"
+	"frontend-challenge/pkg/semver"
 )
 
+// UpdatePrecondition carries the optimistic concurrency checks for an update.
+// Empty fields are not checked.
+type UpdatePrecondition struct {
+	// IfMatch lists the entity tags the client accepts ("*" matches any)
+	IfMatch []string
+	// ExpectedVersion is the document version the client last read
+	ExpectedVersion string
+}
+
+// check verifies the precondition against the stored document
+func (p UpdatePrecondition) check(current *entity.Document) error {
+	if len(p.IfMatch) > 0 {
+		matched := false
+		for _, tag := range p.IfMatch {
+			if tag == "*" || tag == current.ETag() {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return entity.ErrPreconditionFailed
+		}
+	}
+	if p.ExpectedVersion != "" && p.ExpectedVersion != current.Version {
+		return entity.ErrDocumentVersionConflict
+	}
+	return nil
+}
+
+// empty reports whether the precondition checks nothing
+func (p UpdatePrecondition) empty() bool {
+	return len(p.IfMatch) == 0 && p.ExpectedVersion == ""
+}
+
+// Page size bounds for document listings
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// DocumentPage is a page of documents with opaque cursors to its neighbours
+type DocumentPage struct {
+	Documents []*entity.Document
+	// NextCursor is empty on the last page
+	NextCursor string
+	// PrevCursor is empty on the first page
+	PrevCursor string
+}
+
 // DocumentUsecase defines the use cases for documents
 type DocumentUsecase struct {
 	documentRepo repository.DocumentRepository
 	userRepo     repository.UserRepository
+	revisionRepo repository.RevisionRepository
+	groupRepo    repository.GroupRepository
+	lockRepo     repository.LockRepository
+	// lockTTL is how long a lock lasts unless its holder renews it
+	lockTTL time.Duration
+	// commits makes storing a document and recording its revision one
+	// step, so the history follows the order of the writes
+	commits sync.Mutex
 }
 
 // NewDocumentUsecase creates a new instance of DocumentUsecase
-func NewDocumentUsecase(documentRepo repository.DocumentRepository, userRepo repository.UserRepository) *DocumentUsecase {
+func NewDocumentUsecase(
+	documentRepo repository.DocumentRepository,
+	userRepo repository.UserRepository,
+	revisionRepo repository.RevisionRepository,
+	groupRepo repository.GroupRepository,
+	lockRepo repository.LockRepository,
+	lockTTL time.Duration,
+) *DocumentUsecase {
 	return &DocumentUsecase{
 		documentRepo: documentRepo,
 		userRepo:     userRepo,
+		revisionRepo: revisionRepo,
+		groupRepo:    groupRepo,
+		lockRepo:     lockRepo,
+		lockTTL:      lockTTL,
 	}
 }
 
@@ -26,34 +100,428 @@ func (u *DocumentUsecase) GetAllDocuments(ctx context.Context) ([]*entity.Docume
 	return u.documentRepo.GetAll(ctx)
 }
 
-// GetDocumentByID retrieves a document by its ID
+// ListOptions selects, orders and pages a document listing
+type ListOptions struct {
+	Filter repository.DocumentFilter
+	Sort   []repository.SortOrder
+	// Limit defaults to DefaultPageLimit when zero
+	Limit int
+	// Cursor is empty for the first page
+	Cursor string
+}
+
+// ListDocuments retrieves one page of the documents matching the options
+func (u *DocumentUsecase) ListDocuments(ctx context.Context, options ListOptions) (*DocumentPage, error) {
+	limit := options.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		return nil, entity.ErrInvalidPageLimit
+	}
+
+	readableBy, err := u.readableBy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options.Filter.ReadableBy = readableBy
+
+	sort := repository.FormatSort(options.Sort)
+	query := repository.DocumentQuery{
+		Filter: options.Filter,
+		Sort:   options.Sort,
+		Page:   repository.PageRequest{Limit: limit},
+	}
+	if options.Cursor != "" {
+		c, err := decodeCursor(options.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		// A cursor only makes sense in the order it was issued for
+		if c.Sort != sort {
+			return nil, entity.ErrInvalidCursor
+		}
+		if c.Before {
+			query.Page.Before = &c.Key
+		} else {
+			query.Page.After = &c.Key
+		}
+	}
+
+	page, err := u.documentRepo.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DocumentPage{Documents: page.Documents}
+	if page.Next != nil {
+		result.NextCursor = encodeCursor(*page.Next, false, sort)
+	}
+	if page.Prev != nil {
+		result.PrevCursor = encodeCursor(*page.Prev, true, sort)
+	}
+	return result, nil
+}
+
+// SearchDocuments ranks documents by relevance to a free-text query.
+// A zero limit selects DefaultPageLimit.
+func (u *DocumentUsecase) SearchDocuments(ctx context.Context, query string, limit int) ([]repository.SearchHit, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, entity.ErrInvalidSearchQuery
+	}
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		return nil, entity.ErrInvalidPageLimit
+	}
+
+	readableBy, err := u.readableBy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if readableBy == nil {
+		return u.documentRepo.Search(ctx, query, limit)
+	}
+	// Rank everything, then keep the first hits the caller may read
+	hits, err := u.documentRepo.Search(ctx, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	readable := make([]repository.SearchHit, 0, limit)
+	for _, hit := range hits {
+		if hit.Document.Allows(*readableBy, entity.PermissionRead) {
+			readable = append(readable, hit)
+			if len(readable) == limit {
+				break
+			}
+		}
+	}
+	return readable, nil
+}
+
+// LastChange returns a counter that grows with every change to the
+// documents, and when the last change happened
+func (u *DocumentUsecase) LastChange(ctx context.Context) (uint64, time.Time, error) {
+	return u.documentRepo.LastChange(ctx)
+}
+
+// GetDocumentByID retrieves a document the caller may read by its ID.
+// Trashed documents are not found.
 func (u *DocumentUsecase) GetDocumentByID(ctx context.Context, id string) (*entity.Document, error) {
+	return u.getAuthorized(ctx, id, entity.PermissionRead)
+}
+
+// CreateDocument creates a new document owned by the caller. Documents
+// cannot be created on behalf of somebody else.
+func (u *DocumentUsecase) CreateDocument(ctx context.Context, document *entity.Document) error {
+	if err := u.prepareCreate(ctx, document); err != nil {
+		return err
+	}
+	if err := checkUploadedAttachments(&entity.Document{}, document); err != nil {
+		return err
+	}
+	return u.commitCreate(ctx, document)
+}
+
+// prepareCreate makes the caller the owner of a new document and validates
+// it, leaving its attachments to the caller
+func (u *DocumentUsecase) prepareCreate(ctx context.Context, document *entity.Document) error {
+	p, restricted, err := u.principal(ctx)
+	if err != nil {
+		return err
+	}
+	if restricted {
+		if document.OwnerID == "" {
+			document.OwnerID = p.UserID
+		}
+		if document.OwnerID != p.UserID {
+			return &entity.AccessDeniedError{UserID: p.UserID, DocumentID: document.ID, Permission: entity.PermissionAdmin}
+		}
+	}
+	if err := normalizeTags(document); err != nil {
+		return err
+	}
+	return document.Validate()
+}
+
+// UpdateDocument replaces an existing document, rejecting stale writes
+func (u *DocumentUsecase) UpdateDocument(ctx context.Context, document *entity.Document, precondition UpdatePrecondition) (*entity.Document, error) {
+	return u.PatchDocument(ctx, document.ID, precondition, func(current *entity.Document) error {
+		current.Title = document.Title
+		current.Version = document.Version
+		current.Attachments = document.Attachments
+		current.Contributors = document.Contributors
+		current.Tags = document.Tags
+		return nil
+	})
+}
+
+// PatchDocument applies a modification to a copy of the stored document and
+// persists it only if nobody else modified the document in the meantime
+func (u *DocumentUsecase) PatchDocument(ctx context.Context, id string, precondition UpdatePrecondition, apply func(*entity.Document) error) (*entity.Document, error) {
+	current, err := u.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := precondition.check(current); err != nil {
+		return nil, err
+	}
+	return u.save(ctx, current, entity.RevisionUpdated, func(d *entity.Document) error {
+		if err := apply(d); err != nil {
+			return err
+		}
+		keepAttachmentContent(current, d)
+		return checkUploadedAttachments(current, d)
+	})
+}
+
+// DeleteDocument moves a document to the trash
+func (u *DocumentUsecase) DeleteDocument(ctx context.Context, id string) (*entity.Document, error) {
+	current, err := u.GetDocumentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.save(ctx, current, entity.RevisionDeleted, func(d *entity.Document) error {
+		d.MarkDeleted(time.Now())
+		return nil
+	})
+}
+
+// RestoreDocument takes a document out of the trash
+func (u *DocumentUsecase) RestoreDocument(ctx context.Context, id string) (*entity.Document, error) {
 	if id == "" {
 		return nil, entity.ErrInvalidDocumentID
 	}
-	return u.documentRepo.GetByID(ctx, id)
+	current, err := u.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.authorize(ctx, current, entity.PermissionRead); err != nil {
+		return nil, err
+	}
+	if !current.IsDeleted() {
+		return nil, entity.ErrDocumentNotDeleted
+	}
+	return u.save(ctx, current, entity.RevisionRestored, func(d *entity.Document) error {
+		d.Restore()
+		return nil
+	})
 }
 
-// CreateDocument creates a new document
-func (u *DocumentUsecase) CreateDocument(ctx context.Context, document *entity.Document) error {
-	if err := document.Validate(); err != nil {
+// GetTrash retrieves the documents in the trash the caller may read
+func (u *DocumentUsecase) GetTrash(ctx context.Context) ([]*entity.Document, error) {
+	documents, err := u.documentRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	readableBy, err := u.readableBy(ctx)
+	if err != nil || readableBy == nil {
+		return documents, err
+	}
+	readable := []*entity.Document{}
+	for _, document := range documents {
+		if document.Allows(*readableBy, entity.PermissionRead) {
+			readable = append(readable, document)
+		}
+	}
+	return readable, nil
+}
+
+// PurgeTrash permanently deletes documents that have been in the trash
+// for longer than the retention period
+func (u *DocumentUsecase) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	purged, err := u.documentRepo.PurgeDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range purged {
+		if err := u.revisionRepo.DeleteByDocumentID(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(purged), nil
+}
+
+// save applies a modification to a copy of current and stores it only if
+// nobody else modified the document in the meantime. The stored result is
+// recorded in the history under the given revision action. Documents locked
+// by another user are not changed.
+func (u *DocumentUsecase) save(ctx context.Context, current *entity.Document, action string, apply func(*entity.Document) error) (*entity.Document, error) {
+	updated, err := u.prepareUpdate(ctx, current, action, apply)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.commitUpdate(ctx, current, updated, action); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// prepareUpdate applies a modification to a copy of current and runs every
+// check save makes before storing it
+func (u *DocumentUsecase) prepareUpdate(ctx context.Context, current *entity.Document, action string, apply func(*entity.Document) error) (*entity.Document, error) {
+	updated := current.Clone()
+	if err := apply(updated); err != nil {
+		return nil, err
+	}
+	updated.ID = current.ID
+	updated.CreatedAt = current.CreatedAt
+	updated.UpdatedAt = time.Now()
+	if err := normalizeTags(updated); err != nil {
+		return nil, err
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	if err := u.authorize(ctx, current, requiredPermission(action, current, updated)); err != nil {
+		return nil, err
+	}
+	if err := u.checkLock(ctx, current.ID); err != nil {
+		return nil, err
+	}
+	if err := checkVersionOrder(current, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// commitCreate stores a new document together with its first revision.
+// The document is removed again if the revision cannot be recorded.
+func (u *DocumentUsecase) commitCreate(ctx context.Context, document *entity.Document) error {
+	u.commits.Lock()
+	defer u.commits.Unlock()
+
+	if err := u.documentRepo.Create(ctx, document); err != nil {
 		return err
 	}
-	return u.documentRepo.Create(ctx, document)
+	if err := u.recordRevision(ctx, document, entity.RevisionCreated); err != nil {
+		return rollback(err, u.documentRepo.Delete(ctx, document.ID))
+	}
+	return nil
 }
 
-// UpdateDocument updates an existing document
-func (u *DocumentUsecase) UpdateDocument(ctx context.Context, document *entity.Document) error {
-	if err := document.Validate(); err != nil {
+// commitUpdate stores updated in place of current, unless the document
+// changed meanwhile, together with a revision under the given action. The
+// write is undone if the revision cannot be recorded.
+func (u *DocumentUsecase) commitUpdate(ctx context.Context, current, updated *entity.Document, action string) error {
+	u.commits.Lock()
+	defer u.commits.Unlock()
+
+	if err := u.documentRepo.CompareAndUpdate(ctx, updated, current.UpdatedAt); err != nil {
 		return err
 	}
-	return u.documentRepo.Update(ctx, document)
+	if err := u.recordRevision(ctx, updated, action); err != nil {
+		return rollback(err, u.documentRepo.CompareAndUpdate(ctx, current, updated.UpdatedAt))
+	}
+	return nil
 }
 
-// DeleteDocument deletes a document
-func (u *DocumentUsecase) DeleteDocument(ctx context.Context, id string) error {
-	if id == "" {
-		return entity.ErrInvalidDocumentID
+// rollback returns the error that made a write be undone, joined with the
+// error undoing it if that failed too. The cause of the failed rollback is
+// only described, so it does not decide how the error is reported.
+func rollback(err, rollbackErr error) error {
+	if rollbackErr == nil {
+		return err
 	}
-	return u.documentRepo.Delete(ctx, id)
+	return errors.Join(err, fmt.Errorf("%w: %v", entity.ErrRollbackFailed, rollbackErr))
+}
+
+// checkVersionOrder rejects changes that give a document a version with
+// lower precedence than the one it has. Versions stored before they were
+// validated are not compared.
+func checkVersionOrder(current, updated *entity.Document) error {
+	from, err := semver.Parse(current.Version)
+	if err != nil {
+		return nil
+	}
+	to, err := semver.Parse(updated.Version)
+	if err != nil {
+		return entity.ErrInvalidDocumentVersion
+	}
+	if to.Compare(from) < 0 {
+		return entity.ErrVersionRegression
+	}
+	return nil
+}
+
+// keepAttachmentContent gives attachments sent by name only the content
+// the document has under that name, so clients that only know the names
+// do not drop uploads when they write a document back
+func keepAttachmentContent(current, updated *entity.Document) {
+	for i, attachment := range updated.Attachments {
+		if attachment.HasContent() {
+			continue
+		}
+		if stored, ok := current.Attachment(attachment.Name); ok {
+			updated.Attachments[i] = stored
+		}
+	}
+}
+
+// checkUploadedAttachments makes sure clients only reference content that
+// was uploaded to the document: attachments with a checksum must already
+// be part of it, possibly under another name. Without this anyone knowing
+// a checksum could read the content through their own document.
+func checkUploadedAttachments(current, updated *entity.Document) error {
+	uploaded := make(map[entity.Attachment]bool, len(current.Attachments))
+	for _, attachment := range current.Attachments {
+		attachment.Name = ""
+		uploaded[attachment] = true
+	}
+	for _, attachment := range updated.Attachments {
+		if !attachment.HasContent() {
+			continue
+		}
+		attachment.Name = ""
+		if !uploaded[attachment] {
+			return entity.ErrAttachmentNotUploaded
+		}
+	}
+	return nil
+}
+
+// referencedContent returns the checksums of the content that documents, in
+// the trash or not, and their revisions refer to
+func (u *DocumentUsecase) referencedContent(ctx context.Context) (map[string]bool, error) {
+	var documents []*entity.Document
+	err := u.documentRepo.Iterate(ctx, func(document *entity.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	trash, err := u.documentRepo.GetDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	documents = append(documents, trash...)
+
+	referenced := make(map[string]bool)
+	add := func(attachments []entity.Attachment) {
+		for _, attachment := range attachments {
+			if attachment.HasContent() {
+				referenced[attachment.Checksum] = true
+			}
+		}
+	}
+	for _, document := range documents {
+		add(document.Attachments)
+		revisions, err := u.revisionRepo.GetByDocumentID(ctx, document.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, revision := range revisions {
+			add(revision.Snapshot.Attachments)
+		}
+	}
+	return referenced, nil
+}
+
+// recordRevision appends a snapshot of the document to its history,
+// authored by the user performing the request
+func (u *DocumentUsecase) recordRevision(ctx context.Context, document *entity.Document, action string) error {
+	author, _ := ActorFromContext(ctx)
+	return u.revisionRepo.Append(ctx, entity.NewRevision(document, action, author))
 }
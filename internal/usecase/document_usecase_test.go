@@ -0,0 +1,75 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	domainrepo "frontend-challenge/internal/domain/repository"
+	"frontend-challenge/internal/infrastructure/repository"
+	"frontend-challenge/internal/usecase"
+)
+
+var errRevisionStore = errors.New("revision store unavailable")
+
+// failingRevisions cannot record revisions
+type failingRevisions struct {
+	domainrepo.RevisionRepository
+}
+
+func (failingRevisions) Append(ctx context.Context, revision *entity.Revision) error {
+	return errRevisionStore
+}
+
+// undeletableDocuments cannot delete documents
+type undeletableDocuments struct {
+	domainrepo.DocumentRepository
+}
+
+func (undeletableDocuments) Delete(ctx context.Context, id string) error {
+	return errors.New("delete failed")
+}
+
+func TestCreateDocumentReportsFailedRollback(t *testing.T) {
+	documents := repository.NewDocumentRepositoryImpl(repository.NewMemoryCache(time.Minute))
+	groups, err := repository.NewGroupRepositoryImpl("")
+	if err != nil {
+		t.Fatalf("NewGroupRepositoryImpl: %v", err)
+	}
+	tests := []struct {
+		name      string
+		id        string
+		documents domainrepo.DocumentRepository
+		// rolledBack reports whether the document is gone afterwards
+		rolledBack bool
+	}{
+		{"rollback succeeds", "doc1", documents, true},
+		{"rollback fails", "doc2", undeletableDocuments{documents}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := usecase.NewDocumentUsecase(
+				tt.documents,
+				repository.NewUserRepositoryImpl(),
+				failingRevisions{repository.NewRevisionRepositoryImpl()},
+				groups,
+				repository.NewLockRepositoryImpl(),
+				time.Minute,
+			)
+			document := &entity.Document{ID: tt.id, Title: "Plan", Version: "1.0.0"}
+			err := u.CreateDocument(usecase.WithActor(context.Background(), ann), document)
+			if !errors.Is(err, errRevisionStore) {
+				t.Fatalf("got %v, want %v", err, errRevisionStore)
+			}
+			if got := errors.Is(err, entity.ErrRollbackFailed); got == tt.rolledBack {
+				t.Errorf("errors.Is(%v, ErrRollbackFailed) = %v", err, got)
+			}
+			_, err = documents.GetByID(context.Background(), document.ID)
+			if gone := errors.Is(err, entity.ErrDocumentNotFound); gone != tt.rolledBack {
+				t.Errorf("document gone = %v, want %v", gone, tt.rolledBack)
+			}
+		})
+	}
+}
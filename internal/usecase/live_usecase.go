@@ -0,0 +1,600 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/pkg/crdt"
+)
+
+// Live message types
+const (
+	// LiveSnapshot carries the whole state and the replica the client
+	// must use for its operations
+	LiveSnapshot = "snapshot"
+	// LiveOps carries operations made by another replica
+	LiveOps = "ops"
+	// LiveError reports operations that were refused; it is followed by a
+	// snapshot the client must start over from
+	LiveError = "error"
+	// LiveClosed ends the session, e.g. because the document was deleted
+	LiveClosed = "closed"
+)
+
+// serverReplica names the replica of the server, which loads documents into
+// sessions and brings in changes made outside of them
+const serverReplica = "server"
+
+// liveBuffer is how many messages a client may fall behind before it is
+// dropped from its session
+const liveBuffer = 256
+
+// LiveOperations is a batch of CRDT operations on the fields of a document
+// that can be edited live
+type LiveOperations struct {
+	Title        []crdt.RGAOp                     `json:"title,omitempty"`
+	Attachments  []crdt.SetOp[entity.Attachment]  `json:"attachments,omitempty"`
+	Contributors []crdt.SetOp[entity.Contributor] `json:"contributors,omitempty"`
+}
+
+// empty reports whether the batch holds no operations
+func (o *LiveOperations) empty() bool {
+	return len(o.Title) == 0 && len(o.Attachments) == 0 && len(o.Contributors) == 0
+}
+
+// LiveState is the CRDT state of the live fields of a document
+type LiveState struct {
+	Title               []crdt.RGANode                      `json:"title"`
+	Attachments         []crdt.SetEntry[entity.Attachment]  `json:"attachments"`
+	RemovedAttachments  []crdt.ID                           `json:"removedAttachments"`
+	Contributors        []crdt.SetEntry[entity.Contributor] `json:"contributors"`
+	RemovedContributors []crdt.ID                           `json:"removedContributors"`
+}
+
+// LiveMessage is a message for a client of a live session
+type LiveMessage struct {
+	Type string `json:"type"`
+	// Replica is the client's own replica in a snapshot, and the replica
+	// the operations come from otherwise
+	Replica string          `json:"replica,omitempty"`
+	User    *entity.User    `json:"user,omitempty"`
+	State   *LiveState      `json:"state,omitempty"`
+	Ops     *LiveOperations `json:"ops,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// LiveSave is a document stored from a live session
+type LiveSave struct {
+	Document *entity.Document
+	// Editor is who the document was stored on behalf of
+	Editor entity.User
+}
+
+// liveEditor is a user whose changes in a session are not stored yet, with
+// the permission they took
+type liveEditor struct {
+	user       entity.User
+	permission string
+}
+
+// LiveClient is a participant of a live session
+type LiveClient struct {
+	Replica  string
+	user     entity.User
+	session  *liveSession
+	messages chan LiveMessage
+}
+
+// Messages returns what must be sent to the client. The channel is closed
+// when the client leaves or is dropped.
+func (c *LiveClient) Messages() <-chan LiveMessage {
+	return c.messages
+}
+
+// LiveUsecase lets several users edit the title, attachments and
+// contributors of a document at the same time. Each document being edited
+// has a session holding its fields as CRDTs: clients send the operations
+// they make locally, which are applied and relayed to the other clients,
+// and the merged state is stored periodically.
+type LiveUsecase struct {
+	documents *DocumentUsecase
+
+	mu       sync.Mutex
+	sessions map[string]*liveSession
+}
+
+// NewLiveUsecase creates a new instance of LiveUsecase
+func NewLiveUsecase(documents *DocumentUsecase) *LiveUsecase {
+	return &LiveUsecase{
+		documents: documents,
+		sessions:  make(map[string]*liveSession),
+	}
+}
+
+// Join adds the caller, who needs read permission, to the session of a
+// document. The first message of the client is a snapshot.
+func (u *LiveUsecase) Join(ctx context.Context, documentID string) (*LiveClient, error) {
+	actor, ok := ActorFromContext(ctx)
+	if !ok {
+		return nil, entity.ErrUnauthenticated
+	}
+	document, err := u.documents.GetDocumentByID(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	session, ok := u.sessions[documentID]
+	if !ok {
+		session = newLiveSession(document)
+		u.sessions[documentID] = session
+	}
+	return session.join(actor), nil
+}
+
+// Leave removes a client from its session. The session of a document ends
+// with its last client, storing any change not stored yet.
+func (u *LiveUsecase) Leave(ctx context.Context, client *LiveClient) (*LiveSave, error) {
+	session := client.session
+	u.mu.Lock()
+	last := session.leave(client)
+	if last && u.sessions[session.documentID] == session {
+		delete(u.sessions, session.documentID)
+	}
+	u.mu.Unlock()
+
+	if !last {
+		return nil, nil
+	}
+	return u.persist(ctx, session)
+}
+
+// Apply applies operations a client made and relays them to the other
+// clients. Writing takes write permission on the document, changing its
+// contributors admin, and the document must not be locked by another user.
+// When operations are refused the client gets an error and a new snapshot.
+func (u *LiveUsecase) Apply(ctx context.Context, client *LiveClient, ops LiveOperations) error {
+	if ops.empty() {
+		return nil
+	}
+	err := u.authorizeOps(ctx, client, &ops)
+	if err == nil {
+		err = client.session.apply(client, &ops)
+	}
+	if err != nil && !errors.Is(err, entity.ErrLiveSessionClosed) {
+		client.session.reject(client, err)
+	}
+	return err
+}
+
+// Persist stores the sessions with changes not stored yet, first bringing
+// in changes made to their documents outside of them. Changes are stored on
+// behalf of the users who made them, who must still be allowed to; when
+// one of them is not, or another user locked the document, the changes are
+// dropped and the clients start over from the stored document.
+func (u *LiveUsecase) Persist(ctx context.Context) ([]*LiveSave, error) {
+	u.mu.Lock()
+	sessions := make([]*liveSession, 0, len(u.sessions))
+	for _, session := range u.sessions {
+		sessions = append(sessions, session)
+	}
+	u.mu.Unlock()
+
+	var saves []*LiveSave
+	var errs []error
+	for _, session := range sessions {
+		save, err := u.persist(ctx, session)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("document %s: %w", session.documentID, err))
+			continue
+		}
+		if save != nil {
+			saves = append(saves, save)
+		}
+	}
+	return saves, errors.Join(errs...)
+}
+
+// persist stores a session, ending it if its document is gone
+func (u *LiveUsecase) persist(ctx context.Context, session *liveSession) (*LiveSave, error) {
+	save, err := session.persist(ctx, u.documents)
+	if errors.Is(err, entity.ErrDocumentNotFound) {
+		u.mu.Lock()
+		if u.sessions[session.documentID] == session {
+			delete(u.sessions, session.documentID)
+		}
+		u.mu.Unlock()
+		session.close("document was deleted")
+		return nil, nil
+	}
+	return save, err
+}
+
+// authorizeOps checks that the caller may make the operations and that
+// they are well formed
+func (u *LiveUsecase) authorizeOps(ctx context.Context, client *LiveClient, ops *LiveOperations) error {
+	permission := entity.PermissionWrite
+	if len(ops.Contributors) > 0 {
+		permission = entity.PermissionAdmin
+	}
+	document, err := u.documents.getAuthorized(ctx, client.session.documentID, permission)
+	if err != nil {
+		return err
+	}
+	if err := u.documents.checkLock(ctx, document.ID); err != nil {
+		return err
+	}
+
+	for _, op := range ops.Title {
+		if op.Op == crdt.OpInsert && op.ID.Replica != client.Replica {
+			return fmt.Errorf("%w: inserts must use replica %s", crdt.ErrInvalidOp, client.Replica)
+		}
+	}
+	var added []entity.Attachment
+	for _, op := range ops.Attachments {
+		if op.Op != crdt.OpAdd {
+			continue
+		}
+		if op.Tag.Replica != client.Replica {
+			return fmt.Errorf("%w: adds must use replica %s", crdt.ErrInvalidOp, client.Replica)
+		}
+		if op.Key != op.Value.Name {
+			return fmt.Errorf("%w: attachments are keyed by name", crdt.ErrInvalidOp)
+		}
+		if err := entity.ValidateAttachmentName(op.Key); err != nil {
+			return err
+		}
+		added = append(added, op.Value)
+	}
+	// Content can only come from attachments uploaded to the document
+	if err := checkUploadedAttachments(document, &entity.Document{Attachments: added}); err != nil {
+		return err
+	}
+	for _, op := range ops.Contributors {
+		if op.Op != crdt.OpAdd {
+			continue
+		}
+		if op.Tag.Replica != client.Replica {
+			return fmt.Errorf("%w: adds must use replica %s", crdt.ErrInvalidOp, client.Replica)
+		}
+		if op.Key == "" || op.Key != op.Value.ID {
+			return fmt.Errorf("%w: contributors are keyed by user ID", crdt.ErrInvalidOp)
+		}
+		if op.Value.Role != "" {
+			if err := entity.ValidateRole(op.Value.Role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// liveSession holds the live fields of a document and its clients
+type liveSession struct {
+	documentID string
+
+	mu           sync.Mutex
+	clock        *crdt.Clock
+	title        *crdt.RGA
+	attachments  *crdt.ORSet[entity.Attachment]
+	contributors *crdt.ORSet[entity.Contributor]
+	// base is the stored document the state was last reconciled with
+	base *entity.Document
+	// dirty is set when the state has changes base does not have
+	dirty bool
+	// editors made the changes base does not have, by user ID
+	editors map[string]liveEditor
+	// editor made the last change
+	editor  entity.User
+	clients map[*LiveClient]bool
+	// replicas counts the replicas handed out to clients
+	replicas int
+}
+
+// newLiveSession loads a document into a new session
+func newLiveSession(document *entity.Document) *liveSession {
+	s := &liveSession{
+		documentID: document.ID,
+		clock:      crdt.NewClock(serverReplica),
+		clients:    make(map[*LiveClient]bool),
+	}
+	s.load(document)
+	return s
+}
+
+// load replaces the state with the fields of a stored document, dropping
+// the changes it does not have
+func (s *liveSession) load(document *entity.Document) {
+	s.title = crdt.NewRGA()
+	s.attachments = crdt.NewORSet[entity.Attachment]()
+	s.contributors = crdt.NewORSet[entity.Contributor]()
+	// Inserting at the start of an empty title cannot fail
+	_, _ = s.title.Insert(s.clock, 0, document.Title)
+	for _, attachment := range document.Attachments {
+		s.attachments.Add(s.clock, attachment.Name, attachment)
+	}
+	for _, contributor := range document.Contributors {
+		s.contributors.Add(s.clock, contributor.ID, contributor)
+	}
+	s.base = document
+	s.dirty = false
+	s.editors = make(map[string]liveEditor)
+}
+
+// join adds a client and sends it a snapshot
+func (s *liveSession) join(user entity.User) *LiveClient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replicas++
+	client := &LiveClient{
+		Replica:  fmt.Sprintf("r%d", s.replicas),
+		user:     user,
+		session:  s,
+		messages: make(chan LiveMessage, liveBuffer),
+	}
+	s.clients[client] = true
+	s.send(client, s.snapshot(client))
+	return client
+}
+
+// leave removes a client, reporting whether it was the last one
+func (s *liveSession) leave(client *LiveClient) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.clients[client] {
+		delete(s.clients, client)
+		close(client.messages)
+	}
+	return len(s.clients) == 0
+}
+
+// apply applies operations from a client and relays the ones applied
+func (s *liveSession) apply(client *LiveClient, ops *LiveOperations) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.clients[client] {
+		return entity.ErrLiveSessionClosed
+	}
+	var applied LiveOperations
+	err := func() error {
+		for _, op := range ops.Title {
+			if err := s.title.Apply(op); err != nil {
+				return err
+			}
+			s.clock.Observe(op.ID)
+			applied.Title = append(applied.Title, op)
+		}
+		for _, op := range ops.Attachments {
+			if err := s.attachments.Apply(op); err != nil {
+				return err
+			}
+			s.clock.Observe(op.Tag)
+			applied.Attachments = append(applied.Attachments, op)
+		}
+		for _, op := range ops.Contributors {
+			if err := s.contributors.Apply(op); err != nil {
+				return err
+			}
+			s.clock.Observe(op.Tag)
+			applied.Contributors = append(applied.Contributors, op)
+		}
+		return nil
+	}()
+
+	if !applied.empty() {
+		s.dirty = true
+		s.editor = client.user
+		permission := entity.PermissionWrite
+		if len(applied.Contributors) > 0 || s.editors[client.user.ID].permission == entity.PermissionAdmin {
+			permission = entity.PermissionAdmin
+		}
+		s.editors[client.user.ID] = liveEditor{user: client.user, permission: permission}
+		user := client.user
+		s.broadcast(client, LiveMessage{Type: LiveOps, Replica: client.Replica, User: &user, Ops: &applied})
+	}
+	return err
+}
+
+// reject tells a client its operations were refused and resends it the
+// state to start over from
+func (s *liveSession) reject(client *LiveClient, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.clients[client] {
+		return
+	}
+	s.send(client, LiveMessage{Type: LiveError, Error: err.Error()})
+	s.send(client, s.snapshot(client))
+}
+
+// persist reconciles the state with the stored document and stores it if
+// it has changes, on behalf of their editors. A title that is empty while
+// being edited is stored later.
+func (s *liveSession) persist(ctx context.Context, documents *DocumentUsecase) (*LiveSave, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := documents.documentRepo.GetByID(ctx, s.documentID)
+	if err != nil {
+		return nil, err
+	}
+	if current.IsDeleted() {
+		return nil, entity.ErrDocumentNotFound
+	}
+	if !current.UpdatedAt.Equal(s.base.UpdatedAt) {
+		s.rebase(current)
+	}
+	if !s.dirty {
+		return nil, nil
+	}
+
+	// Editors may have lost their permission, or the document may have
+	// been locked, since they sent their operations
+	for _, editor := range s.editors {
+		editorCtx := WithActor(ctx, editor.user)
+		err := documents.authorize(editorCtx, current, editor.permission)
+		if err == nil {
+			err = documents.checkLock(editorCtx, current.ID)
+		}
+		if err != nil {
+			return nil, s.drop(current, err)
+		}
+	}
+
+	author := s.author()
+	updated, err := documents.save(WithActor(ctx, author), current, entity.RevisionUpdated, func(d *entity.Document) error {
+		d.Title = s.title.String()
+		d.Attachments = s.attachments.Values()
+		if len(d.Attachments) == 0 {
+			d.Attachments = nil
+		}
+		d.Contributors = s.contributors.Values()
+		if len(d.Contributors) == 0 {
+			d.Contributors = nil
+		}
+		return checkUploadedAttachments(current, d)
+	})
+	switch {
+	case errors.Is(err, entity.ErrInvalidDocumentTitle):
+		return nil, nil
+	case errors.Is(err, entity.ErrAccessDenied), errors.Is(err, entity.ErrDocumentLocked):
+		return nil, s.drop(current, err)
+	case err != nil:
+		return nil, err
+	}
+	s.base = updated
+	s.dirty = false
+	s.editors = make(map[string]liveEditor)
+	return &LiveSave{Document: updated, Editor: author}, nil
+}
+
+// author returns the editor the state is stored on behalf of: the last one,
+// unless the changes include contributors, which only admins may change
+func (s *liveSession) author() entity.User {
+	if s.editors[s.editor.ID].permission == entity.PermissionAdmin {
+		return s.editor
+	}
+	author := s.editor
+	for _, editor := range s.editors {
+		if editor.permission == entity.PermissionAdmin {
+			author = editor.user
+		}
+	}
+	return author
+}
+
+// drop discards the changes that cannot be stored and has every client
+// start over from the stored document. It returns why, for the logs.
+func (s *liveSession) drop(current *entity.Document, err error) error {
+	s.load(current)
+	for client := range s.clients {
+		s.send(client, LiveMessage{Type: LiveError, Error: "changes dropped: " + err.Error()})
+		s.send(client, s.snapshot(client))
+	}
+	return fmt.Errorf("live changes dropped: %w", err)
+}
+
+// rebase brings changes made to the document outside of the session into
+// the state as operations of the server, and relays them. A title changed
+// outside of the session replaces the one being edited.
+func (s *liveSession) rebase(current *entity.Document) {
+	var ops LiveOperations
+	if current.Title != s.base.Title {
+		// Replacing the whole text stays within its bounds
+		deleted, _ := s.title.Delete(0, s.title.Len())
+		inserted, _ := s.title.Insert(s.clock, 0, current.Title)
+		ops.Title = append(deleted, inserted...)
+	}
+	ops.Attachments = rebaseSet(s.clock, s.attachments, s.base.Attachments, current.Attachments, func(a entity.Attachment) string { return a.Name })
+	ops.Contributors = rebaseSet(s.clock, s.contributors, s.base.Contributors, current.Contributors, func(c entity.Contributor) string { return c.ID })
+
+	s.base = current
+	if !ops.empty() {
+		s.broadcast(nil, LiveMessage{Type: LiveOps, Replica: serverReplica, Ops: &ops})
+	}
+}
+
+// rebaseSet applies to a set the adds and removes that turn from into to
+func rebaseSet[T comparable](clock *crdt.Clock, set *crdt.ORSet[T], from, to []T, key func(T) string) []crdt.SetOp[T] {
+	before := make(map[string]T, len(from))
+	for _, value := range from {
+		before[key(value)] = value
+	}
+	after := make(map[string]bool, len(to))
+	var ops []crdt.SetOp[T]
+	for _, value := range to {
+		k := key(value)
+		after[k] = true
+		if previous, ok := before[k]; !ok || previous != value {
+			ops = append(ops, set.Add(clock, k, value))
+		}
+	}
+	for _, value := range from {
+		if k := key(value); !after[k] {
+			if op, ok := set.Remove(k); ok {
+				ops = append(ops, op)
+			}
+		}
+	}
+	return ops
+}
+
+// close ends the session for all of its clients
+func (s *liveSession) close(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		select {
+		case client.messages <- LiveMessage{Type: LiveClosed, Error: reason}:
+		default:
+		}
+		delete(s.clients, client)
+		close(client.messages)
+	}
+}
+
+// snapshot returns the state for a client
+func (s *liveSession) snapshot(client *LiveClient) LiveMessage {
+	user := client.user
+	return LiveMessage{
+		Type:    LiveSnapshot,
+		Replica: client.Replica,
+		User:    &user,
+		State: &LiveState{
+			Title:               s.title.Nodes(),
+			Attachments:         s.attachments.Entries(),
+			RemovedAttachments:  s.attachments.Removed(),
+			Contributors:        s.contributors.Entries(),
+			RemovedContributors: s.contributors.Removed(),
+		},
+	}
+}
+
+// broadcast sends a message to every client but from
+func (s *liveSession) broadcast(from *LiveClient, message LiveMessage) {
+	for client := range s.clients {
+		if client != from {
+			s.send(client, message)
+		}
+	}
+}
+
+// send queues a message for a client, dropping clients that fell too far
+// behind
+func (s *liveSession) send(client *LiveClient, message LiveMessage) {
+	select {
+	case client.messages <- message:
+	default:
+		delete(s.clients, client)
+		close(client.messages)
+	}
+}
@@ -0,0 +1,207 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/infrastructure/repository"
+	"frontend-challenge/internal/usecase"
+	"frontend-challenge/pkg/crdt"
+)
+
+var (
+	ann = entity.User{ID: "u1", Name: "Ann"}
+	bob = entity.User{ID: "u2", Name: "Bob"}
+)
+
+// newLiveUsecase returns a LiveUsecase over the documents of
+// newDocumentUsecase
+func newLiveUsecase(t *testing.T) (*usecase.LiveUsecase, *usecase.DocumentUsecase, string) {
+	t.Helper()
+	documents, documentID := newDocumentUsecase(t)
+	return usecase.NewLiveUsecase(documents), documents, documentID
+}
+
+// newDocumentUsecase returns a DocumentUsecase over in-memory repositories
+// holding a document owned by Ann that Bob may write
+func newDocumentUsecase(t *testing.T) (*usecase.DocumentUsecase, string) {
+	t.Helper()
+	groups, err := repository.NewGroupRepositoryImpl("")
+	if err != nil {
+		t.Fatalf("NewGroupRepositoryImpl: %v", err)
+	}
+	documents := usecase.NewDocumentUsecase(
+		repository.NewDocumentRepositoryImpl(repository.NewMemoryCache(time.Minute)),
+		repository.NewUserRepositoryImpl(),
+		repository.NewRevisionRepositoryImpl(),
+		groups,
+		repository.NewLockRepositoryImpl(),
+		time.Minute,
+	)
+	document := &entity.Document{
+		ID:      "doc1",
+		Title:   "Plan",
+		Version: "1.0.0",
+		ACL:     []entity.Grant{{UserID: bob.ID, Permission: entity.PermissionWrite}},
+	}
+	if err := documents.CreateDocument(usecase.WithActor(context.Background(), ann), document); err != nil {
+		t.Fatalf("CreateDocument: %v", err)
+	}
+	return documents, document.ID
+}
+
+// liveReplica is the title of a client, as an editor would keep it
+type liveReplica struct {
+	client *usecase.LiveClient
+	clock  *crdt.Clock
+	title  *crdt.RGA
+}
+
+// joinLive joins a session as user and loads the snapshot it starts with
+func joinLive(t *testing.T, live *usecase.LiveUsecase, user entity.User, documentID string) *liveReplica {
+	t.Helper()
+	client, err := live.Join(usecase.WithActor(context.Background(), user), documentID)
+	if err != nil {
+		t.Fatalf("Join as %s: %v", user.Name, err)
+	}
+	replica := &liveReplica{client: client}
+	replica.load(t, receive(t, client, usecase.LiveSnapshot))
+	return replica
+}
+
+// load starts the replica over from a snapshot
+func (r *liveReplica) load(t *testing.T, snapshot usecase.LiveMessage) {
+	t.Helper()
+	if snapshot.Replica != r.client.Replica {
+		t.Fatalf("snapshot for replica %s, want %s", snapshot.Replica, r.client.Replica)
+	}
+	r.clock = crdt.NewClock(snapshot.Replica)
+	for _, node := range snapshot.State.Title {
+		r.clock.Observe(node.ID)
+	}
+	r.title = crdt.LoadRGA(snapshot.State.Title)
+}
+
+// receive returns the next message of a client, which must be of the given
+// type
+func receive(t *testing.T, client *usecase.LiveClient, messageType string) usecase.LiveMessage {
+	t.Helper()
+	select {
+	case message, ok := <-client.Messages():
+		if !ok {
+			t.Fatalf("client %s was dropped", client.Replica)
+		}
+		if message.Type != messageType {
+			t.Fatalf("got %s message %+v, want %s", message.Type, message, messageType)
+		}
+		return message
+	default:
+		t.Fatalf("client %s has no %s message", client.Replica, messageType)
+	}
+	return usecase.LiveMessage{}
+}
+
+// insert edits the title locally and returns the operations to send
+func (r *liveReplica) insert(t *testing.T, index int, text string) usecase.LiveOperations {
+	t.Helper()
+	ops, err := r.title.Insert(r.clock, index, text)
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	return usecase.LiveOperations{Title: ops}
+}
+
+// merge applies the operations another client sent
+func (r *liveReplica) merge(t *testing.T, message usecase.LiveMessage) {
+	t.Helper()
+	for _, op := range message.Ops.Title {
+		if err := r.title.Apply(op); err != nil {
+			t.Fatalf("Apply(%+v): %v", op, err)
+		}
+		r.clock.Observe(op.ID)
+	}
+}
+
+func TestLiveConcurrentEditsConverge(t *testing.T) {
+	live, documents, documentID := newLiveUsecase(t)
+	annReplica := joinLive(t, live, ann, documentID)
+	bobReplica := joinLive(t, live, bob, documentID)
+
+	// Both edit the title before seeing each other's change
+	annOps := annReplica.insert(t, 4, " for Q3")
+	bobOps := bobReplica.insert(t, 0, "Our ")
+	if err := live.Apply(usecase.WithActor(context.Background(), ann), annReplica.client, annOps); err != nil {
+		t.Fatalf("Apply as Ann: %v", err)
+	}
+	if err := live.Apply(usecase.WithActor(context.Background(), bob), bobReplica.client, bobOps); err != nil {
+		t.Fatalf("Apply as Bob: %v", err)
+	}
+	annReplica.merge(t, receive(t, annReplica.client, usecase.LiveOps))
+	bobReplica.merge(t, receive(t, bobReplica.client, usecase.LiveOps))
+
+	const want = "Our Plan for Q3"
+	for name, replica := range map[string]*liveReplica{"Ann": annReplica, "Bob": bobReplica} {
+		if got := replica.title.String(); got != want {
+			t.Errorf("%s has %q, want %q", name, got, want)
+		}
+	}
+
+	saves, err := live.Persist(usecase.Internal(context.Background()))
+	if err != nil {
+		t.Fatalf("Persist: %v", err)
+	}
+	if len(saves) != 1 {
+		t.Fatalf("got %d saves, want 1", len(saves))
+	}
+	stored, err := documents.GetDocumentByID(usecase.WithActor(context.Background(), ann), documentID)
+	if err != nil {
+		t.Fatalf("GetDocumentByID: %v", err)
+	}
+	if stored.Title != want {
+		t.Errorf("stored title %q, want %q", stored.Title, want)
+	}
+}
+
+func TestLivePersistDropsChangesOfRevokedEditors(t *testing.T) {
+	live, documents, documentID := newLiveUsecase(t)
+	annReplica := joinLive(t, live, ann, documentID)
+	bobReplica := joinLive(t, live, bob, documentID)
+
+	if err := live.Apply(usecase.WithActor(context.Background(), bob), bobReplica.client, bobReplica.insert(t, 0, "Our ")); err != nil {
+		t.Fatalf("Apply as Bob: %v", err)
+	}
+	annReplica.merge(t, receive(t, annReplica.client, usecase.LiveOps))
+
+	// Ann takes Bob's write permission away before the session is stored
+	_, _, err := documents.SetAccess(usecase.WithActor(context.Background(), ann), documentID, usecase.AccessControl{}, usecase.UpdatePrecondition{})
+	if err != nil {
+		t.Fatalf("SetAccess: %v", err)
+	}
+
+	_, err = live.Persist(usecase.Internal(context.Background()))
+	if !errors.Is(err, entity.ErrAccessDenied) {
+		t.Fatalf("Persist: got %v, want %v", err, entity.ErrAccessDenied)
+	}
+	for name, replica := range map[string]*liveReplica{"Ann": annReplica, "Bob": bobReplica} {
+		message := receive(t, replica.client, usecase.LiveError)
+		if !strings.Contains(message.Error, "changes dropped") {
+			t.Errorf("%s got error %q", name, message.Error)
+		}
+		replica.load(t, receive(t, replica.client, usecase.LiveSnapshot))
+		if got := replica.title.String(); got != "Plan" {
+			t.Errorf("%s starts over from %q, want %q", name, got, "Plan")
+		}
+	}
+
+	stored, err := documents.GetDocumentByID(usecase.WithActor(context.Background(), ann), documentID)
+	if err != nil {
+		t.Fatalf("GetDocumentByID: %v", err)
+	}
+	if stored.Title != "Plan" {
+		t.Errorf("stored title %q, want %q", stored.Title, "Plan")
+	}
+}
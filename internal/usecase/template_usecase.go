@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// TemplateUsecase handles document templates
+type TemplateUsecase struct {
+	templateRepo repository.TemplateRepository
+	documents    *DocumentUsecase
+}
+
+// NewTemplateUsecase creates a new instance of TemplateUsecase
+func NewTemplateUsecase(templateRepo repository.TemplateRepository, documents *DocumentUsecase) *TemplateUsecase {
+	return &TemplateUsecase{
+		templateRepo: templateRepo,
+		documents:    documents,
+	}
+}
+
+// GetTemplates returns all templates
+func (u *TemplateUsecase) GetTemplates(ctx context.Context) ([]*entity.Template, error) {
+	return u.templateRepo.GetAll(ctx)
+}
+
+// GetTemplate returns a template by ID
+func (u *TemplateUsecase) GetTemplate(ctx context.Context, id string) (*entity.Template, error) {
+	return u.templateRepo.GetByID(ctx, id)
+}
+
+// CreateTemplate validates and stores a new template
+func (u *TemplateUsecase) CreateTemplate(ctx context.Context, template *entity.Template) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	now := time.Now()
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	return u.templateRepo.Create(ctx, template)
+}
+
+// UpdateTemplate replaces an existing template, keeping its creation time
+func (u *TemplateUsecase) UpdateTemplate(ctx context.Context, template *entity.Template) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	current, err := u.templateRepo.GetByID(ctx, template.ID)
+	if err != nil {
+		return err
+	}
+	template.CreatedAt = current.CreatedAt
+	template.UpdatedAt = time.Now()
+	return u.templateRepo.Update(ctx, template)
+}
+
+// DeleteTemplate removes a template. Documents created from it are kept.
+func (u *TemplateUsecase) DeleteTemplate(ctx context.Context, id string) error {
+	return u.templateRepo.Delete(ctx, id)
+}
+
+// CreateDocument creates a document from a template on behalf of the user
+// in the context. A random ID is assigned when documentID is empty.
+func (u *TemplateUsecase) CreateDocument(ctx context.Context, templateID, documentID string) (*entity.Document, error) {
+	template, err := u.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if documentID == "" {
+		documentID = newDocumentID()
+	}
+	actor, _ := ActorFromContext(ctx)
+
+	document := template.Instantiate(entity.TemplateValues{
+		DocumentID: documentID,
+		User:       actor,
+		Now:        time.Now(),
+	})
+	if err := u.documents.CreateDocument(ctx, document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// newDocumentID returns a random (version 4) UUID, the form of the
+// existing document IDs
+func newDocumentID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"frontend-challenge/internal/domain/entity"
+	"frontend-challenge/internal/domain/repository"
+)
+
+// UserUsecase keeps track of the users making requests
+type UserUsecase struct {
+	userRepo repository.UserRepository
+}
+
+// NewUserUsecase creates a new instance of UserUsecase
+func NewUserUsecase(userRepo repository.UserRepository) *UserUsecase {
+	return &UserUsecase{userRepo: userRepo}
+}
+
+// BindActor records an authenticated user, so it can be looked up by ID
+// later, e.g. to be added as a contributor, and binds it to the context as
+// the user performing the request
+func (u *UserUsecase) BindActor(ctx context.Context, user entity.User) context.Context {
+	// Failing to record the user only keeps it from being looked up
+	_ = u.register(ctx, user)
+	return WithActor(ctx, user)
+}
+
+// register creates a user seen for the first time and keeps the name of a
+// known one up to date
+func (u *UserUsecase) register(ctx context.Context, user entity.User) error {
+	known, err := u.userRepo.GetByID(ctx, user.ID)
+	if errors.Is(err, entity.ErrUserNotFound) {
+		return u.userRepo.Create(ctx, &user)
+	}
+	if err != nil || known.Name == user.Name {
+		return err
+	}
+	return u.userRepo.Update(ctx, &user)
+}
@@ -0,0 +1,456 @@
+// Package cbor encodes and decodes CBOR (RFC 8949).
+//
+// Go values are carried in their JSON form, so struct tags and
+// json.Marshaler implementations shape the CBOR document the same way they
+// shape JSON. Integers keep their type, byte strings decode to base64 like
+// []byte does in JSON, and epoch-based date/time tags decode to RFC 3339
+// strings. Other tags are passed through as their content.
+package cbor
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"frontend-challenge/pkg/jsonvalue"
+)
+
+// Decoding errors
+var (
+	ErrInvalidData = errors.New("invalid CBOR data")
+	ErrUnsupported = errors.New("unsupported CBOR data")
+)
+
+// maxDepth bounds the nesting of arrays, maps and tags when decoding
+const maxDepth = 512
+
+// Major types (RFC 8949 section 3.1)
+const (
+	majorUint   = 0
+	majorNegint = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// Tags understood when decoding (RFC 8949 section 3.4)
+const (
+	tagEpochTime      = 1
+	tagPositiveBignum = 2
+	tagNegativeBignum = 3
+)
+
+// indefinite is the additional information of indefinite-length items,
+// and of the break code that ends them
+const indefinite = 31
+
+// Marshal returns the CBOR encoding of v
+func Marshal(v interface{}) ([]byte, error) {
+	value, err := jsonvalue.Of(v)
+	if err != nil {
+		return nil, err
+	}
+	return encode(nil, value)
+}
+
+// Unmarshal decodes a CBOR data item into v, as encoding/json would decode
+// its JSON form
+func Unmarshal(data []byte, v interface{}) error {
+	value, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	raw, err := jsonvalue.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Decode decodes a CBOR data item into a JSON value
+func Decode(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	value, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%w: data after the top-level item", ErrInvalidData)
+	}
+	return value, nil
+}
+
+// encode appends the encoding of a JSON value
+func encode(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xf6), nil
+	case bool:
+		if v {
+			return append(buf, 0xf5), nil
+		}
+		return append(buf, 0xf4), nil
+	case json.Number:
+		return encodeNumber(buf, v)
+	case string:
+		return append(encodeHead(buf, majorText, uint64(len(v))), v...), nil
+	case []interface{}:
+		buf = encodeHead(buf, majorArray, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if buf, err = encode(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case jsonvalue.Object:
+		buf = encodeHead(buf, majorMap, uint64(len(v)))
+		for _, member := range v {
+			buf = append(encodeHead(buf, majorText, uint64(len(member.Key))), member.Key...)
+			var err error
+			if buf, err = encode(buf, member.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", jsonvalue.ErrInvalidValue, value)
+	}
+}
+
+// encodeNumber encodes integers as such, and other numbers as a float32
+// when it is exact
+func encodeNumber(buf []byte, n json.Number) ([]byte, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		if i < 0 {
+			return encodeHead(buf, majorNegint, uint64(-1-i)), nil
+		}
+		return encodeHead(buf, majorUint, uint64(i)), nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return encodeHead(buf, majorUint, u), nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: number %q", jsonvalue.ErrInvalidValue, string(n))
+	}
+	if float64(float32(f)) == f {
+		return binary.BigEndian.AppendUint32(append(buf, majorSimple<<5|26), math.Float32bits(float32(f))), nil
+	}
+	return binary.BigEndian.AppendUint64(append(buf, majorSimple<<5|27), math.Float64bits(f)), nil
+}
+
+// encodeHead appends the initial byte and argument of a data item, in the
+// shortest form
+func encodeHead(buf []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(buf, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major|27), arg)
+	}
+}
+
+// decoder reads data items from a CBOR document
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// errBreak reports the break code where a data item was expected
+var errBreak = fmt.Errorf("%w: unexpected break", ErrInvalidData)
+
+// value decodes the next data item
+func (d *decoder) value(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nested too deeply", ErrUnsupported)
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case majorNegint:
+		if arg <= math.MaxInt64 {
+			return json.Number(strconv.FormatInt(-1-int64(arg), 10)), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return json.Number(n.Neg(n.Add(n, big.NewInt(1))).String()), nil
+	case majorBytes, majorText:
+		data, err := d.str(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorBytes {
+			return base64.StdEncoding.EncodeToString(data), nil
+		}
+		return string(data), nil
+	case majorArray:
+		return d.array(info, arg, depth)
+	case majorMap:
+		return d.mapOf(info, arg, depth)
+	case majorTag:
+		return d.tag(arg, depth)
+	default:
+		return d.simple(info, arg)
+	}
+}
+
+// head reads the initial byte and argument of a data item. The argument of
+// indefinite-length items is zero.
+func (d *decoder) head() (major, info byte, arg uint64, err error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b>>5, b&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		arg, err := d.uint(1 << (info - 24))
+		return major, info, arg, err
+	case info == indefinite:
+		switch major {
+		case majorBytes, majorText, majorArray, majorMap:
+			return major, info, 0, nil
+		case majorSimple:
+			return 0, 0, 0, errBreak
+		}
+	}
+	return 0, 0, 0, fmt.Errorf("%w: initial byte 0x%02x", ErrInvalidData, b)
+}
+
+// str decodes the content of a byte or text string, joining the chunks of
+// an indefinite-length one
+func (d *decoder) str(major, info byte, arg uint64) ([]byte, error) {
+	if info != indefinite {
+		return d.bytes(arg)
+	}
+	var data []byte
+	for {
+		if d.atBreak() {
+			return data, nil
+		}
+		chunkMajor, chunkInfo, chunkArg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == indefinite {
+			return nil, fmt.Errorf("%w: bad chunk in indefinite-length string", ErrInvalidData)
+		}
+		chunk, err := d.bytes(chunkArg)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+}
+
+// array decodes the items of an array
+func (d *decoder) array(info byte, n uint64, depth int) (interface{}, error) {
+	// Every item takes at least one byte
+	if info != indefinite && n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+	array := make([]interface{}, 0, n)
+	for i := uint64(0); info == indefinite || i < n; i++ {
+		if info == indefinite && d.atBreak() {
+			break
+		}
+		value, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+	return array, nil
+}
+
+// mapOf decodes the entries of a map, whose keys must be text strings
+func (d *decoder) mapOf(info byte, n uint64, depth int) (interface{}, error) {
+	if info != indefinite && n > uint64(len(d.data)-d.pos)/2 {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+	object := make(jsonvalue.Object, 0, n)
+	for i := uint64(0); info == indefinite || i < n; i++ {
+		if info == indefinite && d.atBreak() {
+			break
+		}
+		key, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: map key of type %T", ErrUnsupported, key)
+		}
+		value, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		object = append(object, jsonvalue.Member{Key: s, Value: value})
+	}
+	return object, nil
+}
+
+// tag decodes the content of a tag
+func (d *decoder) tag(tag uint64, depth int) (interface{}, error) {
+	if tag == tagPositiveBignum || tag == tagNegativeBignum {
+		return d.bignum(tag)
+	}
+	content, err := d.value(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case tagEpochTime:
+		n, ok := content.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("%w: epoch time of type %T", ErrInvalidData, content)
+		}
+		seconds, err := n.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: epoch time %s", ErrUnsupported, n)
+		}
+		sec, frac := math.Modf(seconds)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC().Format(time.RFC3339Nano), nil
+	default:
+		return content, nil
+	}
+}
+
+// bignum decodes the byte string of a bignum tag
+func (d *decoder) bignum(tag uint64) (interface{}, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != majorBytes {
+		return nil, fmt.Errorf("%w: bignum content of major type %d", ErrInvalidData, major)
+	}
+	data, err := d.str(major, info, arg)
+	if err != nil {
+		return nil, err
+	}
+	n := new(big.Int).SetBytes(data)
+	if tag == tagNegativeBignum {
+		n.Neg(n.Add(n, big.NewInt(1)))
+	}
+	return json.Number(n.String()), nil
+}
+
+// simple decodes simple values and floats
+func (d *decoder) simple(info byte, arg uint64) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		// null and undefined
+		return nil, nil
+	case 25:
+		return floatNumber(float16(uint16(arg)), 32)
+	case 26:
+		return floatNumber(float64(math.Float32frombits(uint32(arg))), 32)
+	case 27:
+		return floatNumber(math.Float64frombits(arg), 64)
+	default:
+		return nil, fmt.Errorf("%w: simple value %d", ErrUnsupported, arg)
+	}
+}
+
+// atBreak consumes the break code that ends an indefinite-length item
+func (d *decoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// uint reads a big-endian unsigned integer of size bytes
+func (d *decoder) uint(size int) (uint64, error) {
+	data, err := d.bytes(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, b := range data {
+		u = u<<8 | uint64(b)
+	}
+	return u, nil
+}
+
+// byte reads one byte
+func (d *decoder) byte() (byte, error) {
+	data, err := d.bytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+// bytes reads n bytes
+func (d *decoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+	data := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return data, nil
+}
+
+// float16 converts a half-precision float (RFC 8949 appendix D)
+func float16(half uint16) float64 {
+	exp := int(half>>10) & 0x1f
+	mant := float64(half & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if half&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// floatNumber returns a float as a JSON number, which cannot be NaN or
+// infinite
+func floatNumber(f float64, bitSize int) (interface{}, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("%w: %v is not a JSON number", ErrUnsupported, f)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize)), nil
+}
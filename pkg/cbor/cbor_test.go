@@ -0,0 +1,322 @@
+package cbor_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"frontend-challenge/pkg/cbor"
+	"frontend-challenge/pkg/jsonvalue"
+)
+
+// mustHex decodes a hex string, ignoring spaces
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return data
+}
+
+// numbers returns n consecutive integers from 0
+func numbers(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+// roundTripValues cover every value kind and every argument size
+var roundTripValues = []struct {
+	name string
+	v    interface{}
+}{
+	{"nil", nil},
+	{"false", false},
+	{"true", true},
+	{"direct uint", 23},
+	{"uint8", 255},
+	{"uint16", 65535},
+	{"uint32", uint32(math.MaxUint32)},
+	{"uint64", uint64(math.MaxUint64)},
+	{"direct negint", -24},
+	{"negint8", -256},
+	{"negint16", -65536},
+	{"negint32", -(int64(1) << 32)},
+	{"min int64", int64(math.MinInt64)},
+	{"float32", 1.5},
+	{"float64", 1.1},
+	{"small float", -2.25e-10},
+	{"large float", 1e300},
+	{"empty string", ""},
+	{"text8", strings.Repeat("x", 255)},
+	{"text16", strings.Repeat("x", 65535)},
+	{"text32", strings.Repeat("x", 65536)},
+	{"unicode", "ü水😀"},
+	{"empty array", []int{}},
+	{"array8", numbers(24)},
+	{"array16", numbers(256)},
+	{"empty map", map[string]int{}},
+	{"map", map[string]interface{}{"a": 1, "b": []int{2, 3}}},
+	{"bytes", []byte{0, 1, 2, 255}},
+	{"time", time.Date(2024, 2, 29, 12, 30, 0, 500, time.UTC)},
+	{"nested", []interface{}{nil, map[string]interface{}{"c": []interface{}{false, -0.5}}}},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range roundTripValues {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := cbor.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			value, err := cbor.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			got, err := jsonvalue.Marshal(value)
+			if err != nil {
+				t.Fatalf("jsonvalue.Marshal: %v", err)
+			}
+			want, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("decoded to %.200s, want %.200s", got, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalIntoStruct(t *testing.T) {
+	type document struct {
+		Title     string     `json:"title"`
+		Size      uint64     `json:"size"`
+		Offset    int64      `json:"offset"`
+		Ratio     float64    `json:"ratio"`
+		Content   []byte     `json:"content"`
+		Tags      []string   `json:"tags"`
+		UpdatedAt time.Time  `json:"updatedAt"`
+		DeletedAt *time.Time `json:"deletedAt"`
+	}
+	in := document{
+		Title:     "Plan",
+		Size:      math.MaxUint64,
+		Offset:    math.MinInt64,
+		Ratio:     0.3,
+		Content:   []byte("hello"),
+		Tags:      []string{"a", "b"},
+		UpdatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	data, err := cbor.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out document
+	if err := cbor.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestMarshalEncoding(t *testing.T) {
+	// RFC 8949 appendix A, for the values Marshal produces. Floats are never
+	// written in half precision, and whole floats are integers in JSON.
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{0, "00"},
+		{1, "01"},
+		{10, "0a"},
+		{23, "17"},
+		{24, "1818"},
+		{25, "1819"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{int64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-10, "29"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{1.5, "fa3fc00000"},
+		{-4.1, "fbc010666666666666"},
+		{1.0e+300, "fb7e37e43c8800759c"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{"", "60"},
+		{"a", "6161"},
+		{"IETF", "6449455446"},
+		{"\"\\", "62225c"},
+		{"ü", "62c3bc"},
+		{"水", "63e6b0b4"},
+		{[]int{}, "80"},
+		{[]int{1, 2, 3}, "83010203"},
+		{[]interface{}{1, []int{2, 3}, []int{4, 5}}, "8301820203820405"},
+		{numbers(26)[1:], "98190102030405060708090a0b0c0d0e0f101112131415161718181819"},
+		{map[string]int{}, "a0"},
+		{map[string]interface{}{"a": 1, "b": []int{2, 3}}, "a26161016162820203"},
+		{[]interface{}{"a", map[string]string{"b": "c"}}, "826161a161626163"},
+	}
+	for _, tt := range tests {
+		got, err := cbor.Marshal(tt.v)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", tt.v, err)
+		}
+		if want := mustHex(t, tt.want); string(got) != string(want) {
+			t.Errorf("Marshal(%v) = %x, want %x", tt.v, got, want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// RFC 8949 appendix A, as JSON values
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"00", `0`},
+		{"1818", `24`},
+		{"1b000000e8d4a51000", `1000000000000`},
+		{"1bffffffffffffffff", `18446744073709551615`},
+		{"c249010000000000000000", `18446744073709551616`},
+		{"3bffffffffffffffff", `-18446744073709551616`},
+		{"c349010000000000000000", `-18446744073709551617`},
+		{"3903e7", `-1000`},
+		{"f90000", `0`},
+		{"f98000", `-0`},
+		{"f93c00", `1`},
+		{"fb3ff199999999999a", `1.1`},
+		{"f93e00", `1.5`},
+		{"f97bff", `65504`},
+		{"fa47c35000", `100000`},
+		{"fa7f7fffff", `3.4028235e+38`},
+		{"fb7e37e43c8800759c", `1e+300`},
+		{"f90001", `5.9604645e-08`},
+		{"f90400", `6.1035156e-05`},
+		{"f9c400", `-4`},
+		{"fbc010666666666666", `-4.1`},
+		{"f4", `false`},
+		{"f5", `true`},
+		{"f6", `null`},
+		{"f7", `null`},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"c11a514b67b0", `"2013-03-21T20:04:00Z"`},
+		{"c1fb41d452d9ec200000", `"2013-03-21T20:04:00.5Z"`},
+		{"d74401020304", `"AQIDBA=="`},
+		{"d818456449455446", `"ZElFVEY="`},
+		{"d82076687474703a2f2f7777772e6578616d706c652e636f6d", `"http://www.example.com"`},
+		{"40", `""`},
+		{"4401020304", `"AQIDBA=="`},
+		{"60", `""`},
+		{"6449455446", `"IETF"`},
+		{"64f0908591", `"𐅑"`},
+		{"80", `[]`},
+		{"8301820203820405", `[1,[2,3],[4,5]]`},
+		{"a0", `{}`},
+		{"a26161016162820203", `{"a":1,"b":[2,3]}`},
+		{"a56161614161626142616361436164614461656145", `{"a":"A","b":"B","c":"C","d":"D","e":"E"}`},
+		{"5f42010243030405ff", `"AQIDBAU="`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"9fff", `[]`},
+		{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+		{"9f01820203820405ff", `[1,[2,3],[4,5]]`},
+		{"83018202039f0405ff", `[1,[2,3],[4,5]]`},
+		{"83019f0203ff820405", `[1,[2,3],[4,5]]`},
+		{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+		{"826161bf61626163ff", `["a",{"b":"c"}]`},
+		{"bf6346756ef563416d7421ff", `{"Fun":true,"Amt":-2}`},
+	}
+	for _, tt := range tests {
+		value, err := cbor.Decode(mustHex(t, tt.data))
+		if err != nil {
+			t.Errorf("Decode(%s): %v", tt.data, err)
+			continue
+		}
+		got, err := jsonvalue.Marshal(value)
+		if err != nil {
+			t.Fatalf("jsonvalue.Marshal: %v", err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Decode(%s) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeRejectsTruncatedData(t *testing.T) {
+	for _, tt := range roundTripValues {
+		data, err := cbor.Marshal(tt.v)
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", tt.name, err)
+		}
+		// Checking every prefix of the large values takes too long
+		if len(data) > 1024 {
+			data = data[:1024]
+		}
+		for n := 0; n < len(data); n++ {
+			if _, err := cbor.Decode(data[:n]); !errors.Is(err, cbor.ErrInvalidData) {
+				t.Errorf("%s cut to %d bytes: got %v, want %v", tt.name, n, err, cbor.ErrInvalidData)
+				break
+			}
+		}
+	}
+}
+
+func TestDecodeRejectsMalformedData(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"reserved additional information", "1c", cbor.ErrInvalidData},
+		{"indefinite-length integer", "1f", cbor.ErrInvalidData},
+		{"break outside an indefinite-length item", "ff", cbor.ErrInvalidData},
+		{"data after the item", "00 00", cbor.ErrInvalidData},
+		{"unterminated indefinite-length array", "9f 01 02", cbor.ErrInvalidData},
+		{"unterminated indefinite-length string", "7f 61 61", cbor.ErrInvalidData},
+		{"text chunk in a byte string", "5f 61 61 ff", cbor.ErrInvalidData},
+		{"nested indefinite-length chunk", "5f 5f ff ff", cbor.ErrInvalidData},
+		{"array longer than the data", "9b ffffffffffffffff", cbor.ErrInvalidData},
+		{"map longer than the data", "bb ffffffffffffffff 6161", cbor.ErrInvalidData},
+		{"string longer than the data", "7b ffffffffffffffff 61", cbor.ErrInvalidData},
+		{"map key that is not text", "a1 01 02", cbor.ErrUnsupported},
+		{"epoch time that is not a number", "c1 6161", cbor.ErrInvalidData},
+		{"bignum that is not a byte string", "c2 01", cbor.ErrInvalidData},
+		{"unassigned simple value", "f0", cbor.ErrUnsupported},
+		{"one-byte simple value", "f8ff", cbor.ErrUnsupported},
+		{"infinity", "f97c00", cbor.ErrUnsupported},
+		{"NaN", "f97e00", cbor.ErrUnsupported},
+		{"negative infinity", "faff800000", cbor.ErrUnsupported},
+		{"nested too deeply", strings.Repeat("81", 1000) + "f6", cbor.ErrUnsupported},
+		{"tags nested too deeply", strings.Repeat("c0", 1000) + "f6", cbor.ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if value, err := cbor.Decode(mustHex(t, tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("got %#v, %v, want %v", value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalRejectsValuesOutsideJSON(t *testing.T) {
+	for _, v := range []interface{}{math.Inf(1), make(chan int), func() {}} {
+		if _, err := cbor.Marshal(v); err == nil {
+			t.Errorf("Marshal(%T) succeeded", v)
+		}
+	}
+}
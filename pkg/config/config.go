@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"strings"
 	"time"
 )
 
@@ -11,17 +12,82 @@ type Config struct {
 	ReadTimeout   time.Duration
 	WriteTimeout  time.Duration
 	IdleTimeout   time.Duration
+	// TrashRetention is how long deleted documents stay restorable
+	TrashRetention time.Duration
+	// BlobDir is where uploaded attachment content is stored
+	BlobDir string
+	// MaxAttachmentSize is the largest attachment accepted, in bytes
+	MaxAttachmentSize int64
+	// MaxUploadSize is the largest attachment upload request, in bytes,
+	// covering all the files it carries
+	MaxUploadSize int64
+	// UploadDir is where resumable uploads are staged until they complete
+	UploadDir string
+	// UploadExpiry is how long a resumable upload may take
+	UploadExpiry time.Duration
+	// MaxImportSize is the largest import body accepted, in bytes
+	MaxImportSize int64
+	// GroupsFile maps group names to their members, for group grants
+	GroupsFile string
+	// LockTTL is how long a document lock lasts without being renewed
+	LockTTL time.Duration
+	// Compression enables gzip and deflate compression of responses
+	Compression bool
+	// CompressionMinSize is the shortest response compressed, in bytes
+	CompressionMinSize int
+	// CompressionLevel is the compress/flate level, from -2 to 9
+	CompressionLevel int
+	// AllowedOrigins may open live editing sessions besides the server's
+	// own origin
+	AllowedOrigins []string
 }
 
 // Load loads the configuration from flags and environment variables
 func Load() *Config {
 	addr := flag.String("addr", "localhost:8080", "http service address")
+	trashRetention := flag.Duration("trash-retention", 30*24*time.Hour, "how long deleted documents stay in the trash")
+	blobDir := flag.String("blob-dir", "data/blobs", "directory for uploaded attachment content")
+	maxAttachmentSize := flag.Int64("max-attachment-size", 32<<20, "largest attachment accepted, in bytes")
+	maxUploadSize := flag.Int64("max-upload-size", 128<<20, "largest attachment upload request, in bytes, for all its files together")
+	uploadDir := flag.String("upload-dir", "data/uploads", "directory for unfinished resumable uploads")
+	uploadExpiry := flag.Duration("upload-expiry", 24*time.Hour, "how long a resumable upload may take")
+	maxImportSize := flag.Int64("max-import-size", 256<<20, "largest document import accepted, in bytes")
+	groupsFile := flag.String("groups-file", "", "JSON file mapping group names to member user IDs")
+	lockTTL := flag.Duration("lock-ttl", 5*time.Minute, "how long a document lock lasts without being renewed")
+	compression := flag.Bool("compression", true, "compress responses for clients accepting gzip or deflate")
+	compressionMinSize := flag.Int("compression-min-size", 1024, "shortest response compressed, in bytes")
+	compressionLevel := flag.Int("compression-level", -1, "compression level, from -2 (Huffman only) to 9 (best); -1 is the default")
+	allowedOrigins := flag.String("allowed-origins", "", "comma-separated origins, besides the server's own, whose pages may open live editing sessions")
 	flag.Parse()
 
 	return &Config{
-		ServerAddress: *addr,
-		ReadTimeout:   15 * time.Second,
-		WriteTimeout:  15 * time.Second,
-		IdleTimeout:   60 * time.Second,
+		ServerAddress:      *addr,
+		ReadTimeout:        15 * time.Second,
+		WriteTimeout:       15 * time.Second,
+		IdleTimeout:        60 * time.Second,
+		TrashRetention:     *trashRetention,
+		BlobDir:            *blobDir,
+		MaxAttachmentSize:  *maxAttachmentSize,
+		MaxUploadSize:      *maxUploadSize,
+		UploadDir:          *uploadDir,
+		UploadExpiry:       *uploadExpiry,
+		MaxImportSize:      *maxImportSize,
+		GroupsFile:         *groupsFile,
+		LockTTL:            *lockTTL,
+		Compression:        *compression,
+		CompressionMinSize: *compressionMinSize,
+		CompressionLevel:   *compressionLevel,
+		AllowedOrigins:     splitList(*allowedOrigins),
 	}
 }
+
+// splitList splits a comma-separated flag value, ignoring empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,63 @@
+// Package crdt implements conflict-free replicated data types: a replicated
+// growable array (RGA) for text and an observed-remove set (OR-Set).
+//
+// Every replica generates operations locally and sends them to the others.
+// Replicas that applied the same operations hold the same state, whatever
+// the order they received concurrent operations in, as long as each
+// operation is delivered after the operations it refers to.
+package crdt
+
+import "errors"
+
+// Operation errors
+var (
+	ErrInvalidOp        = errors.New("invalid operation")
+	ErrUnknownReference = errors.New("operation refers to an unknown element")
+	ErrOutOfRange       = errors.New("index out of range")
+)
+
+// ID identifies an element by the Lamport timestamp of the operation that
+// created it. IDs are unique and totally ordered.
+type ID struct {
+	Counter uint64 `json:"counter"`
+	Replica string `json:"replica"`
+}
+
+// IsZero reports whether the ID is unset
+func (id ID) IsZero() bool {
+	return id.Counter == 0 && id.Replica == ""
+}
+
+// Less orders IDs by counter, breaking ties by replica
+func (id ID) Less(other ID) bool {
+	if id.Counter != other.Counter {
+		return id.Counter < other.Counter
+	}
+	return id.Replica < other.Replica
+}
+
+// Clock is the Lamport clock of a replica
+type Clock struct {
+	Replica string
+	counter uint64
+}
+
+// NewClock creates a clock for the named replica
+func NewClock(replica string) *Clock {
+	return &Clock{Replica: replica}
+}
+
+// Next returns a new ID, greater than every ID the clock has seen
+func (c *Clock) Next() ID {
+	c.counter++
+	return ID{Counter: c.counter, Replica: c.Replica}
+}
+
+// Observe advances the clock past an ID seen in another replica's
+// operation. Replicas must observe every operation they apply so that their
+// own inserts sort after what they have seen.
+func (c *Clock) Observe(id ID) {
+	if id.Counter > c.counter {
+		c.counter = id.Counter
+	}
+}
@@ -0,0 +1,173 @@
+package crdt
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OR-Set operation kinds
+const (
+	OpAdd    = "add"
+	OpRemove = "remove"
+)
+
+// SetOp adds or removes an element of an ORSet
+type SetOp[T any] struct {
+	Op  string `json:"op"`
+	Key string `json:"key"`
+	// Tag identifies an add
+	Tag ID `json:"tag,omitzero"`
+	// Value is the added element
+	Value T `json:"value,omitzero"`
+	// Tags are the adds of the key a remove has observed
+	Tags []ID `json:"tags,omitempty"`
+}
+
+// SetEntry is one add of an ORSet element
+type SetEntry[T any] struct {
+	Key   string `json:"key"`
+	Tag   ID     `json:"tag"`
+	Value T      `json:"value"`
+}
+
+// ORSet is an observed-remove set of elements identified by key. A remove
+// only cancels the adds it has seen, so an add concurrent with a remove
+// wins. When concurrent adds give a key different values, the add with the
+// greatest tag wins.
+type ORSet[T any] struct {
+	// adds holds the live adds of each key by tag
+	adds map[string]map[ID]T
+	// removed holds the tags of cancelled adds, so that they stay cancelled
+	removed map[ID]bool
+}
+
+// NewORSet creates an empty ORSet
+func NewORSet[T any]() *ORSet[T] {
+	return &ORSet[T]{adds: make(map[string]map[ID]T), removed: make(map[ID]bool)}
+}
+
+// LoadORSet recreates an ORSet from the state of another replica, e.g. to
+// start editing from a snapshot of a live session
+func LoadORSet[T any](entries []SetEntry[T], removed []ID) *ORSet[T] {
+	s := NewORSet[T]()
+	for _, id := range removed {
+		s.removed[id] = true
+	}
+	for _, entry := range entries {
+		s.Apply(SetOp[T]{Op: OpAdd, Key: entry.Key, Tag: entry.Tag, Value: entry.Value})
+	}
+	return s
+}
+
+// Entries returns the live adds ordered by tag
+func (s *ORSet[T]) Entries() []SetEntry[T] {
+	var entries []SetEntry[T]
+	for key, adds := range s.adds {
+		for tag, value := range adds {
+			entries = append(entries, SetEntry[T]{Key: key, Tag: tag, Value: value})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Tag.Less(entries[j].Tag) })
+	return entries
+}
+
+// Removed returns the tags of cancelled adds, ordered
+func (s *ORSet[T]) Removed() []ID {
+	removed := make([]ID, 0, len(s.removed))
+	for id := range s.removed {
+		removed = append(removed, id)
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Less(removed[j]) })
+	return removed
+}
+
+// Add adds an element, taking its tag from the clock, and returns the
+// operation to send to other replicas
+func (s *ORSet[T]) Add(clock *Clock, key string, value T) SetOp[T] {
+	op := SetOp[T]{Op: OpAdd, Key: key, Tag: clock.Next(), Value: value}
+	s.Apply(op)
+	return op
+}
+
+// Remove removes an element and returns the operation to send to other
+// replicas. ok is false if the set does not contain the key.
+func (s *ORSet[T]) Remove(key string) (op SetOp[T], ok bool) {
+	adds, ok := s.adds[key]
+	if !ok {
+		return SetOp[T]{}, false
+	}
+	op = SetOp[T]{Op: OpRemove, Key: key}
+	for tag := range adds {
+		op.Tags = append(op.Tags, tag)
+	}
+	sort.Slice(op.Tags, func(i, j int) bool { return op.Tags[i].Less(op.Tags[j]) })
+	s.Apply(op)
+	return op, true
+}
+
+// Apply applies an operation from any replica. Applying an operation again
+// has no effect.
+func (s *ORSet[T]) Apply(op SetOp[T]) error {
+	if op.Key == "" {
+		return fmt.Errorf("%w: missing key", ErrInvalidOp)
+	}
+	switch op.Op {
+	case OpAdd:
+		if op.Tag.IsZero() {
+			return fmt.Errorf("%w: an add needs a tag", ErrInvalidOp)
+		}
+		if s.removed[op.Tag] {
+			return nil
+		}
+		if s.adds[op.Key] == nil {
+			s.adds[op.Key] = make(map[ID]T)
+		}
+		s.adds[op.Key][op.Tag] = op.Value
+		return nil
+	case OpRemove:
+		for _, tag := range op.Tags {
+			s.removed[tag] = true
+			delete(s.adds[op.Key], tag)
+		}
+		if len(s.adds[op.Key]) == 0 {
+			delete(s.adds, op.Key)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidOp, op.Op)
+	}
+}
+
+// Contains reports whether the set contains the key
+func (s *ORSet[T]) Contains(key string) bool {
+	_, ok := s.adds[key]
+	return ok
+}
+
+// Values returns the value of every key, in the order the keys were first
+// added
+func (s *ORSet[T]) Values() []T {
+	type element struct {
+		first, winner ID
+		value         T
+	}
+	elements := make([]element, 0, len(s.adds))
+	for _, adds := range s.adds {
+		var e element
+		for tag, value := range adds {
+			if e.first.IsZero() || tag.Less(e.first) {
+				e.first = tag
+			}
+			if e.winner.Less(tag) {
+				e.winner, e.value = tag, value
+			}
+		}
+		elements = append(elements, e)
+	}
+	sort.Slice(elements, func(i, j int) bool { return elements[i].first.Less(elements[j].first) })
+	values := make([]T, len(elements))
+	for i, e := range elements {
+		values[i] = e.value
+	}
+	return values
+}
@@ -0,0 +1,89 @@
+package crdt_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"frontend-challenge/pkg/crdt"
+)
+
+func TestORSetConcurrentOpsConverge(t *testing.T) {
+	// Both replicas start from a set holding "x", added by a
+	origin := crdt.NewORSet[string]()
+	originClock := crdt.NewClock("a")
+	add := origin.Add(originClock, "x", "first")
+
+	replicas := map[string]*crdt.ORSet[string]{}
+	clocks := map[string]*crdt.Clock{}
+	for _, name := range []string{"a", "b", "c"} {
+		replicas[name] = crdt.LoadORSet(origin.Entries(), origin.Removed())
+		clocks[name] = crdt.NewClock(name)
+		clocks[name].Observe(add.Tag)
+	}
+
+	remove, ok := replicas["a"].Remove("x")
+	if !ok {
+		t.Fatal("Remove: set does not contain x")
+	}
+	batches := [][]crdt.SetOp[string]{
+		{remove},
+		{replicas["b"].Add(clocks["b"], "x", "second"), replicas["b"].Add(clocks["b"], "y", "y")},
+		{replicas["c"].Add(clocks["c"], "x", "third")},
+	}
+
+	for _, order := range permutations(len(batches)) {
+		set := crdt.LoadORSet(origin.Entries(), origin.Removed())
+		for _, i := range order {
+			for _, op := range batches[i] {
+				if err := set.Apply(op); err != nil {
+					t.Fatalf("Apply(%+v): %v", op, err)
+				}
+			}
+		}
+		// The adds concurrent with the remove survive it, and the one
+		// with the greatest tag gives x its value
+		if got, want := set.Values(), []string{"third", "y"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("order %v: got %v, want %v", order, got, want)
+		}
+	}
+}
+
+func TestORSetRemovedAddsStayRemoved(t *testing.T) {
+	clock := crdt.NewClock("a")
+	set := crdt.NewORSet[string]()
+	add := set.Add(clock, "x", "x")
+	if _, ok := set.Remove("x"); !ok {
+		t.Fatal("Remove: set does not contain x")
+	}
+
+	// A replica loaded from the set ignores the add if it arrives late
+	loaded := crdt.LoadORSet(set.Entries(), set.Removed())
+	for _, s := range []*crdt.ORSet[string]{set, loaded} {
+		if err := s.Apply(add); err != nil {
+			t.Fatalf("Apply: %v", err)
+		}
+		if s.Contains("x") {
+			t.Error("removed add came back")
+		}
+	}
+}
+
+func TestORSetApplyRejectsInvalidOps(t *testing.T) {
+	set := crdt.NewORSet[string]()
+	tests := []struct {
+		name string
+		op   crdt.SetOp[string]
+	}{
+		{"missing key", crdt.SetOp[string]{Op: crdt.OpAdd, Tag: crdt.ID{Counter: 1, Replica: "a"}}},
+		{"add without tag", crdt.SetOp[string]{Op: crdt.OpAdd, Key: "x"}},
+		{"unknown op", crdt.SetOp[string]{Op: "move", Key: "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := set.Apply(tt.op); !errors.Is(err, crdt.ErrInvalidOp) {
+				t.Errorf("got %v, want %v", err, crdt.ErrInvalidOp)
+			}
+		})
+	}
+}
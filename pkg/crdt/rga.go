@@ -0,0 +1,178 @@
+package crdt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// RGA operation kinds
+const (
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// RGAOp inserts or deletes one character of an RGA
+type RGAOp struct {
+	Op string `json:"op"`
+	// ID is the inserted character, or the deleted one
+	ID ID `json:"id"`
+	// After is the character the insert goes after, zero for the start
+	After ID `json:"after,omitzero"`
+	// Value is the inserted character
+	Value string `json:"value,omitempty"`
+}
+
+// RGANode is a character of an RGA. Deleted characters are kept as
+// tombstones so that concurrent operations can still refer to them.
+type RGANode struct {
+	ID      ID     `json:"id"`
+	Value   string `json:"value"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// RGA is a replicated growable array of characters, used for text
+type RGA struct {
+	// nodes are in text order, tombstones included
+	nodes []RGANode
+}
+
+// NewRGA creates an empty RGA
+func NewRGA() *RGA {
+	return &RGA{}
+}
+
+// LoadRGA recreates an RGA from the nodes of another replica, e.g. to start
+// editing from a snapshot of a live session
+func LoadRGA(nodes []RGANode) *RGA {
+	return &RGA{nodes: append([]RGANode(nil), nodes...)}
+}
+
+// Nodes returns the characters in text order, tombstones included
+func (r *RGA) Nodes() []RGANode {
+	return append([]RGANode(nil), r.nodes...)
+}
+
+// String returns the text
+func (r *RGA) String() string {
+	var b strings.Builder
+	for _, node := range r.nodes {
+		if !node.Deleted {
+			b.WriteString(node.Value)
+		}
+	}
+	return b.String()
+}
+
+// Len returns the number of characters in the text
+func (r *RGA) Len() int {
+	n := 0
+	for _, node := range r.nodes {
+		if !node.Deleted {
+			n++
+		}
+	}
+	return n
+}
+
+// Insert inserts text before the character at index, taking IDs from the
+// clock, and returns the operations to send to other replicas. index may be
+// the length of the text, to append.
+func (r *RGA) Insert(clock *Clock, index int, text string) ([]RGAOp, error) {
+	if index < 0 || index > r.Len() {
+		return nil, fmt.Errorf("%w: %d", ErrOutOfRange, index)
+	}
+	var after ID
+	if index > 0 {
+		after = r.nodes[r.position(index-1)].ID
+	}
+	var ops []RGAOp
+	for _, char := range text {
+		op := RGAOp{Op: OpInsert, ID: clock.Next(), After: after, Value: string(char)}
+		r.Apply(op)
+		ops = append(ops, op)
+		after = op.ID
+	}
+	return ops, nil
+}
+
+// Delete deletes count characters starting at index and returns the
+// operations to send to other replicas
+func (r *RGA) Delete(index, count int) ([]RGAOp, error) {
+	if index < 0 || count < 0 || index+count > r.Len() {
+		return nil, fmt.Errorf("%w: %d characters at %d", ErrOutOfRange, count, index)
+	}
+	ops := make([]RGAOp, 0, count)
+	for i := 0; i < count; i++ {
+		ops = append(ops, RGAOp{Op: OpDelete, ID: r.nodes[r.position(index+i)].ID})
+	}
+	for _, op := range ops {
+		r.Apply(op)
+	}
+	return ops, nil
+}
+
+// Apply applies an operation from any replica. Applying an operation again
+// has no effect.
+func (r *RGA) Apply(op RGAOp) error {
+	switch op.Op {
+	case OpInsert:
+		if op.ID.IsZero() || utf8.RuneCountInString(op.Value) != 1 {
+			return fmt.Errorf("%w: an insert needs an ID and a single character", ErrInvalidOp)
+		}
+		if r.index(op.ID) >= 0 {
+			return nil
+		}
+		pos := 0
+		if !op.After.IsZero() {
+			after := r.index(op.After)
+			if after < 0 {
+				return ErrUnknownReference
+			}
+			pos = after + 1
+		}
+		// Concurrent inserts at the same place are ordered by descending
+		// ID; characters inserted after them always have greater IDs
+		for pos < len(r.nodes) && op.ID.Less(r.nodes[pos].ID) {
+			pos++
+		}
+		r.nodes = append(r.nodes, RGANode{})
+		copy(r.nodes[pos+1:], r.nodes[pos:])
+		r.nodes[pos] = RGANode{ID: op.ID, Value: op.Value}
+		return nil
+	case OpDelete:
+		i := r.index(op.ID)
+		if i < 0 {
+			return ErrUnknownReference
+		}
+		r.nodes[i].Deleted = true
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidOp, op.Op)
+	}
+}
+
+// index returns the position of a node in nodes, or -1
+func (r *RGA) index(id ID) int {
+	for i, node := range r.nodes {
+		if node.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// position returns the position in nodes of the visible character at
+// index, which callers have checked to be in range
+func (r *RGA) position(index int) int {
+	for i, node := range r.nodes {
+		if node.Deleted {
+			continue
+		}
+		if index == 0 {
+			return i
+		}
+		index--
+	}
+	panic(fmt.Sprintf("crdt: index %d out of range", index))
+}
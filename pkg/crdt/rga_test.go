@@ -0,0 +1,189 @@
+package crdt_test
+
+import (
+	"errors"
+	"testing"
+
+	"frontend-challenge/pkg/crdt"
+)
+
+// rgaReplica is an RGA with its own clock
+type rgaReplica struct {
+	clock *crdt.Clock
+	text  *crdt.RGA
+}
+
+// newRGAReplicas returns replicas that all start from text, typed by the
+// first one and loaded by the others from its nodes
+func newRGAReplicas(t *testing.T, text string, names ...string) []*rgaReplica {
+	t.Helper()
+	origin := &rgaReplica{clock: crdt.NewClock(names[0]), text: crdt.NewRGA()}
+	ops, err := origin.text.Insert(origin.clock, 0, text)
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	replicas := []*rgaReplica{origin}
+	for _, name := range names[1:] {
+		replica := &rgaReplica{clock: crdt.NewClock(name), text: crdt.LoadRGA(origin.text.Nodes())}
+		for _, op := range ops {
+			replica.clock.Observe(op.ID)
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas
+}
+
+// applyAll applies batches of operations to an RGA in the given order
+func applyAll(t *testing.T, text *crdt.RGA, batches [][]crdt.RGAOp, order []int) {
+	t.Helper()
+	for _, i := range order {
+		for _, op := range batches[i] {
+			if err := text.Apply(op); err != nil {
+				t.Fatalf("Apply(%+v): %v", op, err)
+			}
+		}
+	}
+}
+
+// permutations returns every order of n items
+func permutations(n int) [][]int {
+	if n == 0 {
+		return [][]int{{}}
+	}
+	var orders [][]int
+	for _, rest := range permutations(n - 1) {
+		for i := 0; i <= len(rest); i++ {
+			order := append(append(append([]int{}, rest[:i]...), n-1), rest[i:]...)
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+func TestRGAConcurrentEditsConverge(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		edit  []func(r *rgaReplica) ([]crdt.RGAOp, error)
+		want  string
+	}{
+		{
+			name:  "inserts at the same place",
+			start: "",
+			edit: []func(r *rgaReplica) ([]crdt.RGAOp, error){
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Insert(r.clock, 0, "abc") },
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Insert(r.clock, 0, "xyz") },
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Insert(r.clock, 0, "123") },
+			},
+			// Runs stay together, the greatest replica first
+			want: "123xyzabc",
+		},
+		{
+			name:  "inserts and deletes around each other",
+			start: "hello",
+			edit: []func(r *rgaReplica) ([]crdt.RGAOp, error){
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Insert(r.clock, 5, " world") },
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Insert(r.clock, 0, "oh, ") },
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Delete(1, 3) },
+			},
+			want: "oh, ho world",
+		},
+		{
+			name:  "insert after a concurrently deleted character",
+			start: "abc",
+			edit: []func(r *rgaReplica) ([]crdt.RGAOp, error){
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Insert(r.clock, 2, "X") },
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Delete(1, 1) },
+				func(r *rgaReplica) ([]crdt.RGAOp, error) { return r.text.Delete(0, 3) },
+			},
+			want: "X",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := make([][]crdt.RGAOp, len(tt.edit))
+			for i, edit := range tt.edit {
+				replica := newRGAReplicas(t, tt.start, "a", "b", "c")[i]
+				ops, err := edit(replica)
+				if err != nil {
+					t.Fatalf("edit %d: %v", i, err)
+				}
+				batches[i] = ops
+			}
+
+			for _, order := range permutations(len(batches)) {
+				replica := newRGAReplicas(t, tt.start, "a", "b", "c")[0]
+				applyAll(t, replica.text, batches, order)
+				if got := replica.text.String(); got != tt.want {
+					t.Errorf("order %v: got %q, want %q", order, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRGAApplyIsIdempotent(t *testing.T) {
+	replica := newRGAReplicas(t, "ab", "a")[0]
+	ops, err := replica.text.Insert(replica.clock, 1, "x")
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	deletes, err := replica.text.Delete(0, 1)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, op := range append(ops, deletes...) {
+		if err := replica.text.Apply(op); err != nil {
+			t.Fatalf("Apply(%+v) again: %v", op, err)
+		}
+	}
+	if got := replica.text.String(); got != "xb" {
+		t.Errorf("got %q, want %q", got, "xb")
+	}
+}
+
+func TestRGAOutOfRange(t *testing.T) {
+	replica := newRGAReplicas(t, "abc", "a")[0]
+	tests := []struct {
+		name string
+		edit func() ([]crdt.RGAOp, error)
+	}{
+		{"insert past the end", func() ([]crdt.RGAOp, error) { return replica.text.Insert(replica.clock, 4, "x") }},
+		{"insert before the start", func() ([]crdt.RGAOp, error) { return replica.text.Insert(replica.clock, -1, "x") }},
+		{"delete past the end", func() ([]crdt.RGAOp, error) { return replica.text.Delete(2, 2) }},
+		{"delete a negative count", func() ([]crdt.RGAOp, error) { return replica.text.Delete(1, -1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.edit(); !errors.Is(err, crdt.ErrOutOfRange) {
+				t.Errorf("got %v, want %v", err, crdt.ErrOutOfRange)
+			}
+			if got := replica.text.String(); got != "abc" {
+				t.Errorf("text changed to %q", got)
+			}
+		})
+	}
+}
+
+func TestRGAApplyRejectsInvalidOps(t *testing.T) {
+	text := crdt.NewRGA()
+	tests := []struct {
+		name string
+		op   crdt.RGAOp
+		want error
+	}{
+		{"insert without ID", crdt.RGAOp{Op: crdt.OpInsert, Value: "x"}, crdt.ErrInvalidOp},
+		{"insert of several characters", crdt.RGAOp{Op: crdt.OpInsert, ID: crdt.ID{Counter: 1, Replica: "a"}, Value: "xy"}, crdt.ErrInvalidOp},
+		{"insert after an unknown character", crdt.RGAOp{Op: crdt.OpInsert, ID: crdt.ID{Counter: 2, Replica: "a"}, After: crdt.ID{Counter: 1, Replica: "b"}, Value: "x"}, crdt.ErrUnknownReference},
+		{"delete of an unknown character", crdt.RGAOp{Op: crdt.OpDelete, ID: crdt.ID{Counter: 1, Replica: "b"}}, crdt.ErrUnknownReference},
+		{"unknown op", crdt.RGAOp{Op: "move"}, crdt.ErrInvalidOp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := text.Apply(tt.op); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
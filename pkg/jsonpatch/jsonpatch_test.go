@@ -0,0 +1,393 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"frontend-challenge/pkg/jsonpatch"
+)
+
+// assertJSONEqual compares two JSON documents regardless of member order
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("result %s is not JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("expected %s is not JSON: %v", want, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// apply decodes and applies a JSON Patch
+func apply(document, patch string) ([]byte, error) {
+	p, err := jsonpatch.DecodePatch([]byte(patch))
+	if err != nil {
+		return nil, err
+	}
+	return p.Apply([]byte(document))
+}
+
+func TestPatchRFC6902Examples(t *testing.T) {
+	// RFC 6902 appendix A
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+	}{
+		{
+			"A.1 adding an object member",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			`{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			"A.2 adding an array element",
+			`{"foo": ["bar", "baz"]}`,
+			`[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			`{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			"A.3 removing an object member",
+			`{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "remove", "path": "/baz"}]`,
+			`{"foo": "bar"}`,
+		},
+		{
+			"A.4 removing an array element",
+			`{"foo": ["bar", "qux", "baz"]}`,
+			`[{"op": "remove", "path": "/foo/1"}]`,
+			`{"foo": ["bar", "baz"]}`,
+		},
+		{
+			"A.5 replacing a value",
+			`{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			`{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			"A.6 moving a value",
+			`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			"A.7 moving an array element",
+			`{"foo": ["all", "grass", "cows", "eat"]}`,
+			`[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			`{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			"A.8 testing a value: success",
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			"A.10 adding a nested member object",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			`{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			"A.11 ignoring unrecognized elements",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			`{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			"A.14 ~ escape ordering",
+			`{"/": 9, "~1": 10}`,
+			`[{"op": "test", "path": "/~01", "value": 10}]`,
+			`{"/": 9, "~1": 10}`,
+		},
+		{
+			"A.16 adding an array value",
+			`{"foo": ["bar"]}`,
+			`[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			`{"foo": ["bar", ["abc", "def"]]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := apply(tt.document, tt.patch)
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestPatchRFC6902Errors(t *testing.T) {
+	// RFC 6902 appendix A
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     error
+	}{
+		{
+			"A.9 testing a value: error",
+			`{"baz": "qux"}`,
+			`[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			jsonpatch.ErrTestFailed,
+		},
+		{
+			"A.12 adding to a nonexistent target",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			jsonpatch.ErrUnprocessable,
+		},
+		{
+			// The last op wins, and there is nothing at /baz to remove
+			"A.13 invalid JSON Patch document",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz", "value": "qux", "op": "remove"}]`,
+			jsonpatch.ErrUnprocessable,
+		},
+		{
+			"A.15 comparing strings and numbers",
+			`{"/": 9, "~1": 10}`,
+			`[{"op": "test", "path": "/~01", "value": "10"}]`,
+			jsonpatch.ErrTestFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := apply(tt.document, tt.patch)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %s, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+	}{
+		{
+			"add replaces an existing member",
+			`{"a": 1}`,
+			`[{"op": "add", "path": "/a", "value": 2}]`,
+			`{"a": 2}`,
+		},
+		{
+			"add null",
+			`{"a": 1}`,
+			`[{"op": "add", "path": "/b", "value": null}]`,
+			`{"a": 1, "b": null}`,
+		},
+		{
+			"add at the end of an array by index",
+			`[1, 2]`,
+			`[{"op": "add", "path": "/2", "value": 3}]`,
+			`[1, 2, 3]`,
+		},
+		{
+			"add replaces the whole document",
+			`{"a": 1}`,
+			`[{"op": "add", "path": "", "value": [1]}]`,
+			`[1]`,
+		},
+		{
+			"replace the whole document",
+			`{"a": 1}`,
+			`[{"op": "replace", "path": "", "value": {"b": 2}}]`,
+			`{"b": 2}`,
+		},
+		{
+			"replace an array element",
+			`{"a": [1, 2, 3]}`,
+			`[{"op": "replace", "path": "/a/1", "value": "x"}]`,
+			`{"a": [1, "x", 3]}`,
+		},
+		{
+			"copy is deep",
+			`{"a": {"b": 1}}`,
+			`[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "add", "path": "/c/d", "value": 2}]`,
+			`{"a": {"b": 1}, "c": {"b": 1, "d": 2}}`,
+		},
+		{
+			"move to the same place",
+			`{"a": 1}`,
+			`[{"op": "move", "from": "/a", "path": "/a"}]`,
+			`{"a": 1}`,
+		},
+		{
+			"keys with slashes and tildes",
+			`{"a/b": {"~": 1}}`,
+			`[{"op": "replace", "path": "/a~1b/~0", "value": 2}]`,
+			`{"a/b": {"~": 2}}`,
+		},
+		{
+			"empty key",
+			`{"": 1}`,
+			`[{"op": "test", "path": "/", "value": 1}, {"op": "remove", "path": "/"}]`,
+			`{}`,
+		},
+		{
+			"test compares objects and arrays",
+			`{"a": {"b": [1, {"c": null}]}}`,
+			`[{"op": "test", "path": "/a", "value": {"b": [1.0, {"c": null}]}}]`,
+			`{"a": {"b": [1, {"c": null}]}}`,
+		},
+		{"empty patch", `{"a": 1}`, `[]`, `{"a": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := apply(tt.document, tt.patch)
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestDecodePatchRejectsInvalidDocuments(t *testing.T) {
+	for _, patch := range []string{
+		``,
+		`{"op": "add", "path": "/a", "value": 1}`,
+		`[{"op": "add", "path": "/a"}]`,
+		`[{"op": "replace", "path": "/a"}]`,
+		`[{"op": "test", "path": "/a"}]`,
+		`[{"op": "move", "path": "/a"}]`,
+		`[{"op": "copy", "from": "a", "path": "/b"}]`,
+		`[{"op": "remove", "path": "a"}]`,
+		`[{"op": "merge", "path": "/a", "value": 1}]`,
+		`[{"path": "/a", "value": 1}]`,
+		`[{"op": 1, "path": "/a"}]`,
+		`[{"op": "add", "path": "/a", "value": 1}`,
+	} {
+		if _, err := jsonpatch.DecodePatch([]byte(patch)); !errors.Is(err, jsonpatch.ErrInvalidPatch) {
+			t.Errorf("DecodePatch(%s): got %v, want %v", patch, err, jsonpatch.ErrInvalidPatch)
+		}
+	}
+}
+
+func TestPatchUnprocessable(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+	}{
+		{"remove a missing member", `{}`, `[{"op": "remove", "path": "/a"}]`},
+		{"remove the whole document", `{}`, `[{"op": "remove", "path": ""}]`},
+		{"replace a missing member", `{}`, `[{"op": "replace", "path": "/a", "value": 1}]`},
+		{"add past the end of an array", `[1]`, `[{"op": "add", "path": "/2", "value": 1}]`},
+		{"remove past the end of an array", `[1]`, `[{"op": "remove", "path": "/1"}]`},
+		{"remove the end of an array", `[1]`, `[{"op": "remove", "path": "/-"}]`},
+		{"index with a leading zero", `[1, 2]`, `[{"op": "remove", "path": "/01"}]`},
+		{"negative index", `[1, 2]`, `[{"op": "remove", "path": "/-1"}]`},
+		{"index that is not a number", `[1, 2]`, `[{"op": "replace", "path": "/a", "value": 0}]`},
+		{"traverse into a scalar", `{"a": 1}`, `[{"op": "add", "path": "/a/b", "value": 1}]`},
+		{"test a missing member", `{}`, `[{"op": "test", "path": "/a", "value": null}]`},
+		{"move into a child", `{"a": {"b": {}}}`, `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`},
+		{"copy a missing member", `{}`, `[{"op": "copy", "from": "/a", "path": "/b"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := apply(tt.document, tt.patch)
+			if !errors.Is(err, jsonpatch.ErrUnprocessable) {
+				t.Errorf("got %s, %v, want %v", got, err, jsonpatch.ErrUnprocessable)
+			}
+		})
+	}
+}
+
+func TestPatchIsAtomic(t *testing.T) {
+	patch, err := jsonpatch.DecodePatch([]byte(`[
+		{"op": "add", "path": "/b", "value": 2},
+		{"op": "test", "path": "/a", "value": 0}
+	]`))
+	if err != nil {
+		t.Fatalf("DecodePatch: %v", err)
+	}
+	document := []byte(`{"a": 1}`)
+	if got, err := patch.Apply(document); !errors.Is(err, jsonpatch.ErrTestFailed) || got != nil {
+		t.Fatalf("got %s, %v, want %v", got, err, jsonpatch.ErrTestFailed)
+	}
+	if string(document) != `{"a": 1}` {
+		t.Errorf("document changed to %s", document)
+	}
+}
+
+func TestMergePatchRFC7386Examples(t *testing.T) {
+	// RFC 7386 appendix A, unchanged in RFC 7396
+	tests := []struct {
+		document string
+		patch    string
+		want     string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := jsonpatch.MergePatch([]byte(tt.document), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %v", tt.document, tt.patch, err)
+			continue
+		}
+		assertJSONEqual(t, got, tt.want)
+	}
+}
+
+func TestMergePatchRFC7386Example(t *testing.T) {
+	// RFC 7386 section 3
+	document := `{
+		"title": "Goodbye!",
+		"author": {"givenName": "John", "familyName": "Doe"},
+		"tags": ["example", "sample"],
+		"content": "This will be unchanged"
+	}`
+	patch := `{
+		"title": "Hello!",
+		"phoneNumber": "+01-123-456-7890",
+		"author": {"familyName": null},
+		"tags": ["example"]
+	}`
+	got, err := jsonpatch.MergePatch([]byte(document), []byte(patch))
+	if err != nil {
+		t.Fatalf("MergePatch: %v", err)
+	}
+	assertJSONEqual(t, got, `{
+		"title": "Hello!",
+		"author": {"givenName": "John"},
+		"tags": ["example"],
+		"content": "This will be unchanged",
+		"phoneNumber": "+01-123-456-7890"
+	}`)
+}
+
+func TestMergePatchRejectsMalformedInput(t *testing.T) {
+	if _, err := jsonpatch.MergePatch([]byte(`{"a":1}`), []byte(`{"a":`)); !errors.Is(err, jsonpatch.ErrInvalidPatch) {
+		t.Errorf("malformed patch: got %v, want %v", err, jsonpatch.ErrInvalidPatch)
+	}
+	if _, err := jsonpatch.MergePatch([]byte(`{"a":`), []byte(`{}`)); err == nil {
+		t.Error("malformed document was accepted")
+	}
+}
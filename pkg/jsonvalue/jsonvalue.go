@@ -0,0 +1,178 @@
+// Package jsonvalue holds JSON values as Go values that keep the order of
+// object members, so they can be carried over to other encodings as they
+// would be written in JSON.
+//
+// A value is nil, a bool, a json.Number, a string, a []interface{} or an
+// Object.
+package jsonvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidValue is returned for Go values outside of the JSON data model
+var ErrInvalidValue = errors.New("invalid JSON value")
+
+// Member is a named value of an object
+type Member struct {
+	Key   string
+	Value interface{}
+}
+
+// Object is a JSON object with its members in order
+type Object []Member
+
+// Get returns the value of the first member with the given key
+func (o Object) Get(key string) (interface{}, bool) {
+	for _, member := range o {
+		if member.Key == key {
+			return member.Value, true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON writes the members in order
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(member.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Of returns the JSON value of v, as encoding/json marshals it
+func Of(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses a JSON document
+func Parse(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := parseValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err == nil {
+		return nil, fmt.Errorf("%w: data after the top-level value", ErrInvalidValue)
+	}
+	return value, nil
+}
+
+// parseValue reads the next value from the token stream
+func parseValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '[':
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := parseValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err := decoder.Token()
+		return array, err
+	case '{':
+		object := Object{}
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, Member{Key: token.(string), Value: value})
+		}
+		_, err := decoder.Token()
+		return object, err
+	default:
+		return nil, fmt.Errorf("%w: unexpected %v", ErrInvalidValue, delim)
+	}
+}
+
+// Marshal writes a value as JSON. Unlike json.Marshal it rejects Go
+// values outside of the JSON data model.
+func Marshal(value interface{}) ([]byte, error) {
+	if err := check(value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// check reports values outside of the JSON data model
+func check(value interface{}) error {
+	switch v := value.(type) {
+	case nil, bool, string:
+		return nil
+	case json.Number:
+		if !IsNumber(string(v)) {
+			return fmt.Errorf("%w: number %q", ErrInvalidValue, string(v))
+		}
+		return nil
+	case []interface{}:
+		for _, item := range v {
+			if err := check(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Object:
+		for _, member := range v {
+			if err := check(member.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %T", ErrInvalidValue, value)
+	}
+}
+
+// IsNumber reports whether s is a JSON number
+func IsNumber(s string) bool {
+	// json.Valid allows space around the value; a number ends with a digit
+	if s == "" || (s[0] != '-' && !isDigit(s[0])) || !isDigit(s[len(s)-1]) {
+		return false
+	}
+	return json.Valid([]byte(s))
+}
+
+// isDigit reports whether b is an ASCII digit
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
@@ -0,0 +1,183 @@
+package jsonvalue_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"frontend-challenge/pkg/jsonvalue"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want interface{}
+	}{
+		{"null", `null`, nil},
+		{"true", `true`, true},
+		{"false", `false`, false},
+		{"integer", `-12`, json.Number("-12")},
+		// Numbers keep the text they were written with
+		{"decimal", `1.50`, json.Number("1.50")},
+		{"exponent", `1e21`, json.Number("1e21")},
+		{"string", `"aé\n"`, "aé\n"},
+		{"empty array", `[]`, []interface{}{}},
+		{"array", `[1,"a",null]`, []interface{}{json.Number("1"), "a", nil}},
+		{"empty object", `{}`, jsonvalue.Object{}},
+		{
+			"object keeps member order",
+			`{"b":1,"a":{"d":[],"c":true}}`,
+			jsonvalue.Object{
+				{Key: "b", Value: json.Number("1")},
+				{Key: "a", Value: jsonvalue.Object{{Key: "d", Value: []interface{}{}}, {Key: "c", Value: true}}},
+			},
+		},
+		{
+			"object keeps duplicate keys",
+			`{"a":1,"a":2}`,
+			jsonvalue.Object{{Key: "a", Value: json.Number("1")}, {Key: "a", Value: json.Number("2")}},
+		},
+		{"surrounding space", " \n[ 1 ]\t", []interface{}{json.Number("1")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonvalue.Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse(%s): %v", tt.data, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%s) = %#v, want %#v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsMalformedInput(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`{`,
+		`[1,`,
+		`[1,]`,
+		`{"a"}`,
+		`{"a":}`,
+		`{1:2}`,
+		`tru`,
+		`"open`,
+		`01`,
+		`1 2`,
+		`{} []`,
+		`]`,
+	} {
+		if value, err := jsonvalue.Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) = %#v, want an error", data, value)
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	for _, data := range []string{
+		`null`,
+		`true`,
+		`-0.5e-3`,
+		`"q\"\\é"`,
+		`[[],{},[{"z":0,"a":[1,2]}]]`,
+		`{"b":1,"a":2,"b":3}`,
+	} {
+		value, err := jsonvalue.Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", data, err)
+		}
+		got, err := jsonvalue.Marshal(value)
+		if err != nil {
+			t.Fatalf("Marshal(%#v): %v", value, err)
+		}
+		if string(got) != data {
+			t.Errorf("Marshal(Parse(%s)) = %s", data, got)
+		}
+	}
+}
+
+func TestMarshalRejectsValuesOutsideJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"int", 1},
+		{"map", map[string]interface{}{"a": 1}},
+		{"struct", struct{}{}},
+		{"malformed number", json.Number("1.")},
+		{"NaN", json.Number("NaN")},
+		{"nested in an array", []interface{}{"a", 1.5}},
+		{"nested in an object", jsonvalue.Object{{Key: "a", Value: []string{}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := jsonvalue.Marshal(tt.value); !errors.Is(err, jsonvalue.ErrInvalidValue) {
+				t.Errorf("got %v, want %v", err, jsonvalue.ErrInvalidValue)
+			}
+		})
+	}
+}
+
+func TestOfKeepsFieldOrder(t *testing.T) {
+	type document struct {
+		Title string   `json:"title"`
+		ID    string   `json:"id"`
+		Tags  []string `json:"tags,omitempty"`
+		Size  int      `json:"size"`
+	}
+	value, err := jsonvalue.Of(document{Title: "Plan", ID: "1", Size: 3})
+	if err != nil {
+		t.Fatalf("Of: %v", err)
+	}
+	want := jsonvalue.Object{
+		{Key: "title", Value: "Plan"},
+		{Key: "id", Value: "1"},
+		{Key: "size", Value: json.Number("3")},
+	}
+	if !reflect.DeepEqual(value, want) {
+		t.Errorf("Of = %#v, want %#v", value, want)
+	}
+	if got, ok := want.Get("id"); !ok || got != "1" {
+		t.Errorf("Get(id) = %v, %v", got, ok)
+	}
+	if _, ok := want.Get("tags"); ok {
+		t.Error("Get(tags) found a member that was omitted")
+	}
+}
+
+func TestIsNumber(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"0", true},
+		{"-0", true},
+		{"12", true},
+		{"-1.5", true},
+		{"1e3", true},
+		{"1E-3", true},
+		{"1.5e+3", true},
+		{"", false},
+		{"-", false},
+		{"+1", false},
+		{"01", false},
+		{"1.", false},
+		{".5", false},
+		{"1e", false},
+		{"0x10", false},
+		{"NaN", false},
+		{"Infinity", false},
+		{" 1", false},
+		{"1 ", false},
+		{"1\n", false},
+		{`"1"`, false},
+	}
+	for _, tt := range tests {
+		if got := jsonvalue.IsNumber(tt.s); got != tt.want {
+			t.Errorf("IsNumber(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,418 @@
+// Package msgpack encodes and decodes MessagePack
+// (https://github.com/msgpack/msgpack/blob/master/spec.md).
+//
+// Go values are carried in their JSON form, so struct tags and
+// json.Marshaler implementations shape the MessagePack document the same way
+// they shape JSON. Integers keep their type, binary data decodes to base64
+// like []byte does in JSON, and timestamps decode to RFC 3339 strings.
+package msgpack
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"frontend-challenge/pkg/jsonvalue"
+)
+
+// Decoding errors
+var (
+	ErrInvalidData = errors.New("invalid MessagePack data")
+	ErrUnsupported = errors.New("unsupported MessagePack data")
+)
+
+// maxDepth bounds the nesting of arrays and maps when decoding
+const maxDepth = 512
+
+// timestampType is the extension type of timestamps
+const timestampType = -1
+
+// Marshal returns the MessagePack encoding of v
+func Marshal(v interface{}) ([]byte, error) {
+	value, err := jsonvalue.Of(v)
+	if err != nil {
+		return nil, err
+	}
+	return encode(nil, value)
+}
+
+// Unmarshal decodes a MessagePack document into v, as encoding/json
+// would decode its JSON form
+func Unmarshal(data []byte, v interface{}) error {
+	value, err := Decode(data)
+	if err != nil {
+		return err
+	}
+	raw, err := jsonvalue.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Decode decodes a MessagePack document into a JSON value
+func Decode(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	value, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%w: data after the top-level value", ErrInvalidData)
+	}
+	return value, nil
+}
+
+// encode appends the encoding of a JSON value
+func encode(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if v {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case json.Number:
+		return encodeNumber(buf, v)
+	case string:
+		return encodeString(buf, v), nil
+	case []interface{}:
+		buf = encodeHeader(buf, len(v), 0x90, 15, 0xdc, 0xdd)
+		for _, item := range v {
+			var err error
+			if buf, err = encode(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case jsonvalue.Object:
+		buf = encodeHeader(buf, len(v), 0x80, 15, 0xde, 0xdf)
+		for _, member := range v {
+			buf = encodeString(buf, member.Key)
+			var err error
+			if buf, err = encode(buf, member.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", jsonvalue.ErrInvalidValue, value)
+	}
+}
+
+// encodeNumber uses the smallest integer format that holds the number, or
+// a float32 when it is exact
+func encodeNumber(buf []byte, n json.Number) ([]byte, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		switch {
+		case i >= 0:
+			return encodeUint(buf, uint64(i)), nil
+		case i >= -32:
+			return append(buf, byte(i)), nil
+		case i >= math.MinInt8:
+			return append(buf, 0xd0, byte(i)), nil
+		case i >= math.MinInt16:
+			return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(i)), nil
+		case i >= math.MinInt32:
+			return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(i)), nil
+		default:
+			return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(i)), nil
+		}
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return encodeUint(buf, u), nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: number %q", jsonvalue.ErrInvalidValue, string(n))
+	}
+	if float64(float32(f)) == f {
+		return binary.BigEndian.AppendUint32(append(buf, 0xca), math.Float32bits(float32(f))), nil
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(f)), nil
+}
+
+// encodeUint appends a non-negative integer
+func encodeUint(buf []byte, u uint64) []byte {
+	switch {
+	case u <= 0x7f:
+		return append(buf, byte(u))
+	case u <= math.MaxUint8:
+		return append(buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(u))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), u)
+	}
+}
+
+// encodeString appends a UTF-8 string
+func encodeString(buf []byte, s string) []byte {
+	if len(s) <= math.MaxUint8 && len(s) > 31 {
+		buf = append(buf, 0xd9, byte(len(s)))
+	} else {
+		buf = encodeHeader(buf, len(s), 0xa0, 31, 0xda, 0xdb)
+	}
+	return append(buf, s...)
+}
+
+// encodeHeader appends the type and length of a string, array or map,
+// using the fixed format for lengths up to fixMax
+func encodeHeader(buf []byte, n int, fix byte, fixMax int, code16, code32 byte) []byte {
+	switch {
+	case n <= fixMax:
+		return append(buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, code16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, code32), uint32(n))
+	}
+}
+
+// decoder reads values from a MessagePack document
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// value decodes the next value
+func (d *decoder) value(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: nested too deeply", ErrUnsupported)
+	}
+	b, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return json.Number(strconv.Itoa(int(b))), nil
+	case b >= 0xe0:
+		return json.Number(strconv.Itoa(int(int8(b)))), nil
+	case b&0xf0 == 0x80:
+		return d.mapOf(int(b&0x0f), depth)
+	case b&0xf0 == 0x90:
+		return d.arrayOf(int(b&0x0f), depth)
+	case b&0xe0 == 0xa0:
+		return d.str(int(b & 0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(b - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(b - 0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		bits, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(float64(math.Float32frombits(uint32(bits))), 32)
+	case 0xcb:
+		bits, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return floatNumber(math.Float64frombits(bits), 64)
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(u, 10)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		u, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend the big-endian value to 64 bits
+		shift := 64 - 8*size
+		return json.Number(strconv.FormatInt(int64(u<<shift)>>shift, 10)), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (b - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(b - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(b - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.arrayOf(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.length(b - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.mapOf(n, depth)
+	default:
+		return nil, fmt.Errorf("%w: type byte 0x%02x", ErrInvalidData, b)
+	}
+}
+
+// arrayOf decodes n array items
+func (d *decoder) arrayOf(n, depth int) (interface{}, error) {
+	// Every item takes at least one byte
+	if n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+	array := make([]interface{}, n)
+	for i := range array {
+		value, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		array[i] = value
+	}
+	return array, nil
+}
+
+// mapOf decodes n map entries, whose keys must be strings
+func (d *decoder) mapOf(n, depth int) (interface{}, error) {
+	if n > (len(d.data)-d.pos)/2 {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+	object := make(jsonvalue.Object, n)
+	for i := range object {
+		key, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: map key of type %T", ErrUnsupported, key)
+		}
+		value, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		object[i] = jsonvalue.Member{Key: s, Value: value}
+	}
+	return object, nil
+}
+
+// ext decodes extension data of n bytes. Only timestamps are understood.
+func (d *decoder) ext(n int) (interface{}, error) {
+	typ, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != timestampType {
+		return nil, fmt.Errorf("%w: extension type %d", ErrUnsupported, int8(typ))
+	}
+
+	var sec int64
+	var nsec uint32
+	switch n {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(data))
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		nsec, sec = uint32(v>>34), int64(v&(1<<34-1))
+	case 12:
+		nsec, sec = binary.BigEndian.Uint32(data), int64(binary.BigEndian.Uint64(data[4:]))
+	default:
+		return nil, fmt.Errorf("%w: timestamp of %d bytes", ErrInvalidData, n)
+	}
+	if nsec >= 1e9 {
+		return nil, fmt.Errorf("%w: timestamp nanoseconds out of range", ErrInvalidData)
+	}
+	return time.Unix(sec, int64(nsec)).UTC().Format(time.RFC3339Nano), nil
+}
+
+// str decodes a string of n bytes
+func (d *decoder) str(n int) (interface{}, error) {
+	data, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// length reads a big-endian length of 1, 2 or 4 bytes, by size class
+func (d *decoder) length(class byte) (int, error) {
+	n, err := d.uint(1 << class)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)) {
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+	return int(n), nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes
+func (d *decoder) uint(size int) (uint64, error) {
+	data, err := d.bytes(size)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, b := range data {
+		u = u<<8 | uint64(b)
+	}
+	return u, nil
+}
+
+// byte reads one byte
+func (d *decoder) byte() (byte, error) {
+	data, err := d.bytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+// bytes reads n bytes
+func (d *decoder) bytes(n int) ([]byte, error) {
+	if n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidData)
+	}
+	data := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return data, nil
+}
+
+// floatNumber returns a float as a JSON number, which cannot be NaN or
+// infinite
+func floatNumber(f float64, bitSize int) (interface{}, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("%w: %v is not a JSON number", ErrUnsupported, f)
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize)), nil
+}
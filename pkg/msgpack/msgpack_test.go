@@ -0,0 +1,291 @@
+package msgpack_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"frontend-challenge/pkg/jsonvalue"
+	"frontend-challenge/pkg/msgpack"
+)
+
+// mustHex decodes a hex string, ignoring spaces
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return data
+}
+
+// numbers returns n consecutive integers from 0
+func numbers(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+// keys returns a map of n entries
+func keys(n int) map[string]int {
+	m := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		m[strings.Repeat("k", i+1)] = i
+	}
+	return m
+}
+
+// roundTripValues cover every value kind and every size class of the
+// formats the encoder picks
+var roundTripValues = []struct {
+	name string
+	v    interface{}
+}{
+	{"nil", nil},
+	{"false", false},
+	{"true", true},
+	{"positive fixint", 127},
+	{"uint8", 255},
+	{"uint16", 65535},
+	{"uint32", uint32(math.MaxUint32)},
+	{"uint64", uint64(math.MaxUint64)},
+	{"max int64", int64(math.MaxInt64)},
+	{"negative fixint", -32},
+	{"int8", -128},
+	{"int16", -32768},
+	{"int32", int32(math.MinInt32)},
+	{"int64", int64(math.MinInt64)},
+	{"float32", 1.5},
+	{"float64", 0.1},
+	{"small float", -2.25e-10},
+	{"large float", 1e300},
+	{"empty string", ""},
+	{"fixstr", strings.Repeat("x", 31)},
+	{"str8", strings.Repeat("x", 255)},
+	{"str16", strings.Repeat("x", 65535)},
+	{"str32", strings.Repeat("x", 65536)},
+	{"unicode", "ü€😀"},
+	{"empty array", []int{}},
+	{"fixarray", numbers(15)},
+	{"array16", numbers(16)},
+	{"array32", numbers(65536)},
+	{"empty map", map[string]int{}},
+	{"fixmap", keys(15)},
+	{"map16", keys(16)},
+	{"bytes", []byte{0, 1, 2, 255}},
+	{"time", time.Date(2024, 2, 29, 12, 30, 0, 500, time.UTC)},
+	{"nested", map[string]interface{}{"a": []interface{}{nil, true, map[string]interface{}{"b": -1.25}}}},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range roundTripValues {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := msgpack.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			value, err := msgpack.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			got, err := jsonvalue.Marshal(value)
+			if err != nil {
+				t.Fatalf("jsonvalue.Marshal: %v", err)
+			}
+			want, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("decoded to %.200s, want %.200s", got, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalIntoStruct(t *testing.T) {
+	type contributor struct {
+		ID   string `json:"id"`
+		Role string `json:"role,omitempty"`
+	}
+	type document struct {
+		Title        string        `json:"title"`
+		Size         uint64        `json:"size"`
+		Offset       int64         `json:"offset"`
+		Ratio        float64       `json:"ratio"`
+		Content      []byte        `json:"content"`
+		Contributors []contributor `json:"contributors"`
+		UpdatedAt    time.Time     `json:"updatedAt"`
+		DeletedAt    *time.Time    `json:"deletedAt"`
+	}
+	in := document{
+		Title:        "Plan",
+		Size:         math.MaxUint64,
+		Offset:       math.MinInt64,
+		Ratio:        0.3,
+		Content:      []byte("hello"),
+		Contributors: []contributor{{ID: "u1", Role: "editor"}, {ID: "u2"}},
+		UpdatedAt:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	data, err := msgpack.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out document
+	if err := msgpack.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestMarshalEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"nil", nil, "c0"},
+		{"false", false, "c2"},
+		{"true", true, "c3"},
+		{"zero", 0, "00"},
+		{"positive fixint", 127, "7f"},
+		{"uint8", 128, "cc 80"},
+		{"uint16", 256, "cd 0100"},
+		{"uint32", 65536, "ce 00010000"},
+		{"uint64", uint64(1) << 32, "cf 0000000100000000"},
+		{"negative fixint", -1, "ff"},
+		{"smallest negative fixint", -32, "e0"},
+		{"int8", -33, "d0 df"},
+		{"int16", -129, "d1 ff7f"},
+		{"int32", -32769, "d2 ffff7fff"},
+		{"int64", int64(math.MinInt32) - 1, "d3 ffffffff7fffffff"},
+		{"exact float32", 1.5, "ca 3fc00000"},
+		{"float64", 0.1, "cb 3fb999999999999a"},
+		{"empty fixstr", "", "a0"},
+		{"fixstr", "a", "a1 61"},
+		{"str8", strings.Repeat("a", 32), "d9 20" + strings.Repeat("61", 32)},
+		{"str16", strings.Repeat("a", 256), "da 0100" + strings.Repeat("61", 256)},
+		{"fixarray", []int{1, 2}, "92 01 02"},
+		{"array16", numbers(16), "dc 0010 000102030405060708090a0b0c0d0e0f"},
+		{"fixmap", map[string]int{"a": 1}, "81 a161 01"},
+		{"struct keeps field order", struct {
+			B int `json:"b"`
+			A int `json:"a"`
+		}{1, 2}, "82 a162 01 a161 02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := msgpack.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if want := mustHex(t, tt.want); string(got) != string(want) {
+				t.Errorf("got % x, want % x", got, want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// Formats other encoders may pick, which Marshal never does
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"uint16 holding a small value", "cd 0001", `1`},
+		{"int8 holding a positive value", "d0 05", `5`},
+		{"int64 holding -1", "d3 ffffffffffffffff", `-1`},
+		{"float32", "ca 3fc00000", `1.5`},
+		{"float64", "cb 7e37e43c8800759c", `1e+300`},
+		{"str8 holding a short string", "d9 01 61", `"a"`},
+		{"array32", "dd 00000001 c0", `[null]`},
+		{"map32", "df 00000001 a161 c3", `{"a":true}`},
+		{"bin8", "c4 03 010203", `"AQID"`},
+		{"bin16", "c5 0003 010203", `"AQID"`},
+		{"bin32", "c6 00000003 010203", `"AQID"`},
+		{"timestamp32", "d6 ff 00000001", `"1970-01-01T00:00:01Z"`},
+		{"timestamp64", "d7 ff 7735940000000001", `"1970-01-01T00:00:01.5Z"`},
+		{"timestamp96", "c7 0c ff 00000001 ffffffffffffffff", `"1969-12-31T23:59:59.000000001Z"`},
+		{"map keeps duplicate keys in order", "82 a162 01 a162 02", `{"b":1,"b":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := msgpack.Decode(mustHex(t, tt.data))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			got, err := jsonvalue.Marshal(value)
+			if err != nil {
+				t.Fatalf("jsonvalue.Marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsTruncatedData(t *testing.T) {
+	for _, tt := range roundTripValues {
+		data, err := msgpack.Marshal(tt.v)
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", tt.name, err)
+		}
+		// Checking every prefix of the large values takes too long
+		if len(data) > 1024 {
+			data = data[:1024]
+		}
+		for n := 0; n < len(data); n++ {
+			if _, err := msgpack.Decode(data[:n]); !errors.Is(err, msgpack.ErrInvalidData) {
+				t.Errorf("%s cut to %d bytes: got %v, want %v", tt.name, n, err, msgpack.ErrInvalidData)
+				break
+			}
+		}
+	}
+}
+
+func TestDecodeRejectsMalformedData(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"never used type", "c1", msgpack.ErrInvalidData},
+		{"data after the value", "c0 c0", msgpack.ErrInvalidData},
+		{"array longer than the data", "dd ffffffff", msgpack.ErrInvalidData},
+		{"map longer than the data", "df ffffffff a161", msgpack.ErrInvalidData},
+		{"string longer than the data", "db ffffffff 61", msgpack.ErrInvalidData},
+		{"map key that is not a string", "81 01 02", msgpack.ErrUnsupported},
+		{"unknown extension", "d4 01 00", msgpack.ErrUnsupported},
+		{"timestamp of a bad size", "c7 05 ff 0000000000", msgpack.ErrInvalidData},
+		{"timestamp nanoseconds out of range", "c7 0c ff 3b9aca00 0000000000000000", msgpack.ErrInvalidData},
+		{"NaN", "cb 7ff8000000000001", msgpack.ErrUnsupported},
+		{"infinity", "ca 7f800000", msgpack.ErrUnsupported},
+		{"nested too deeply", strings.Repeat("91", 1000) + "c0", msgpack.ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if value, err := msgpack.Decode(mustHex(t, tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("got %#v, %v, want %v", value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalRejectsValuesOutsideJSON(t *testing.T) {
+	for _, v := range []interface{}{math.NaN(), make(chan int), func() {}} {
+		if _, err := msgpack.Marshal(v); err == nil {
+			t.Errorf("Marshal(%T) succeeded", v)
+		}
+	}
+}
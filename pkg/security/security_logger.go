@@ -128,6 +128,26 @@ func (sl *SecurityLogger) LogAuthenticationFailure(r *http.Request, username str
 	sl.LogEvent(event)
 }
 
+// LogAccessDenied logs a request refused for lack of permission
+func (sl *SecurityLogger) LogAccessDenied(r *http.Request, userID, resource, permission string) {
+	event := SecurityEvent{
+		EventType:   "ACCESS_DENIED",
+		IPAddress:   sl.getClientIP(r),
+		UserAgent:   r.UserAgent(),
+		RequestPath: r.URL.Path,
+		Method:      r.Method,
+		Severity:    "MEDIUM",
+		Message:     "Access denied",
+		Details: map[string]interface{}{
+			"user_id":    userID,
+			"resource":   resource,
+			"permission": permission,
+		},
+	}
+
+	sl.LogEvent(event)
+}
+
 // getClientIP gets the real client IP
 func (sl *SecurityLogger) getClientIP(r *http.Request) string {
 	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
@@ -0,0 +1,204 @@
+// Package semver parses, compares and increments Semantic Versions 2.0.0
+// (https://semver.org).
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion is returned for strings that are not semantic versions
+var ErrInvalidVersion = errors.New("invalid semantic version")
+
+// Version is a parsed semantic version
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+// Parse parses a version such as "1.4.0-rc.1+build.7". A leading "v" is
+// not accepted.
+func Parse(s string) (Version, error) {
+	var v Version
+
+	rest, build, hasBuild := strings.Cut(s, "+")
+	if hasBuild {
+		ids, err := identifiers(build, false)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w %q: build metadata: %v", ErrInvalidVersion, s, err)
+		}
+		v.Build = ids
+	}
+	core, prerelease, hasPrerelease := strings.Cut(rest, "-")
+	if hasPrerelease {
+		ids, err := identifiers(prerelease, true)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w %q: pre-release: %v", ErrInvalidVersion, s, err)
+		}
+		v.Prerelease = ids
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("%w %q: expected MAJOR.MINOR.PATCH", ErrInvalidVersion, s)
+	}
+	numbers := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := numeric(part)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w %q: %v", ErrInvalidVersion, s, err)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+// String formats the version
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or +1 depending on the precedence of v and other.
+// Build metadata does not take part in precedence.
+func (v Version) Compare(other Version) int {
+	if c := compareUint(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// A pre-release has lower precedence than the release itself
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+// Bump kinds
+const (
+	Major = "major"
+	Minor = "minor"
+	Patch = "patch"
+)
+
+// Bump returns the next version for a major, minor or patch release.
+// Pre-release and build metadata are dropped; a pre-release of exactly the
+// requested release is promoted instead of skipped, so 2.0.0-rc.1 bumps to
+// 2.0.0 as a major release.
+func (v Version) Bump(kind string) (Version, error) {
+	pre := len(v.Prerelease) > 0
+	next := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch kind {
+	case Major:
+		if !pre || v.Minor != 0 || v.Patch != 0 {
+			next = Version{Major: v.Major + 1}
+		}
+	case Minor:
+		if !pre || v.Patch != 0 {
+			next = Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+	case Patch:
+		if !pre {
+			next.Patch++
+		}
+	default:
+		return Version{}, fmt.Errorf("unknown bump %q (expected major, minor or patch)", kind)
+	}
+	return next, nil
+}
+
+// identifiers splits and checks dot-separated pre-release or build identifiers
+func identifiers(s string, prerelease bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, errors.New("empty identifier")
+		}
+		for _, c := range id {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+				return nil, fmt.Errorf("invalid character %q in %q", c, id)
+			}
+		}
+		if prerelease && isNumeric(id) && len(id) > 1 && id[0] == '0' {
+			return nil, fmt.Errorf("numeric identifier %q has a leading zero", id)
+		}
+	}
+	return ids, nil
+}
+
+// numeric parses a MAJOR, MINOR or PATCH number
+func numeric(s string) (uint64, error) {
+	if !isNumeric(s) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%q has a leading zero", s)
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// compareIdentifier compares pre-release identifiers: numeric ones
+// numerically and below alphanumeric ones, which compare in ASCII order
+func compareIdentifier(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		if c := compareUint(uint64(len(a)), uint64(len(b))); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,177 @@
+package semver_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"frontend-challenge/pkg/semver"
+)
+
+func mustParse(t *testing.T, s string) semver.Version {
+	t.Helper()
+	v, err := semver.Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return v
+}
+
+func TestComparePrecedence(t *testing.T) {
+	// SemVer 2.0.0 items 11.2 and 11.4, each list in ascending precedence
+	for _, ordered := range [][]string{
+		{"1.0.0", "2.0.0", "2.1.0", "2.1.1"},
+		{"1.0.0-alpha", "1.0.0"},
+		{
+			"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+			"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0",
+		},
+	} {
+		for i := range ordered {
+			for j := range ordered {
+				a, b := mustParse(t, ordered[i]), mustParse(t, ordered[j])
+				want := 0
+				switch {
+				case i < j:
+					want = -1
+				case i > j:
+					want = 1
+				}
+				if got := a.Compare(b); got != want {
+					t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCompareIgnoresBuildMetadata(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0+20130313144700", "1.0.0", 0},
+		{"1.0.0-beta+exp.sha.5114f85", "1.0.0-beta+exp.sha.1", 0},
+		{"1.0.0-alpha+001", "1.0.0", -1},
+		{"1.0.0+21AF26D3----117B344092BD", "1.0.1", -1},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.a).Compare(mustParse(t, tt.b)); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareNumbers(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.9.0", "1.10.0", -1},
+		{"1.10.0", "1.11.0", -1},
+		{"0.0.9", "0.0.10", -1},
+		{"1.0.0-2", "1.0.0-10", -1},
+		{"1.0.0-999", "1.0.0-a", -1},
+		{"1.0.0-Z", "1.0.0-a", -1},
+		{"1.0.0-a-b", "1.0.0-a.b", 1},
+		{"18446744073709551615.0.0", "18446744073709551614.0.0", 1},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.a).Compare(mustParse(t, tt.b)); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s    string
+		want semver.Version
+	}{
+		{"0.0.0", semver.Version{}},
+		{"1.2.3", semver.Version{Major: 1, Minor: 2, Patch: 3}},
+		{"1.0.0-alpha", semver.Version{Major: 1, Prerelease: []string{"alpha"}}},
+		{"1.0.0-0.3.7", semver.Version{Major: 1, Prerelease: []string{"0", "3", "7"}}},
+		{"1.0.0-x.7.z.92", semver.Version{Major: 1, Prerelease: []string{"x", "7", "z", "92"}}},
+		{"1.0.0-x-y-z.--", semver.Version{Major: 1, Prerelease: []string{"x-y-z", "--"}}},
+		{"1.0.0-alpha+001", semver.Version{Major: 1, Prerelease: []string{"alpha"}, Build: []string{"001"}}},
+		{"1.0.0+exp.sha.5114f85", semver.Version{Major: 1, Build: []string{"exp", "sha", "5114f85"}}},
+		{"1.0.0+21AF26D3----117B344092BD", semver.Version{Major: 1, Build: []string{"21AF26D3----117B344092BD"}}},
+		{"1.4.0-rc.1+build.7", semver.Version{Major: 1, Minor: 4, Prerelease: []string{"rc", "1"}, Build: []string{"build", "7"}}},
+	}
+	for _, tt := range tests {
+		got := mustParse(t, tt.s)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.s, got, tt.want)
+		}
+		if got.String() != tt.s {
+			t.Errorf("Parse(%q).String() = %q", tt.s, got.String())
+		}
+	}
+}
+
+func TestParseRejectsInvalidVersions(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"1",
+		"1.2",
+		"1.2.3.4",
+		"v1.2.3",
+		" 1.2.3",
+		"1.2.3 ",
+		"01.2.3",
+		"1.02.3",
+		"1.2.03",
+		"-1.2.3",
+		"1.2.-3",
+		"1..3",
+		"a.b.c",
+		"18446744073709551616.0.0",
+		"1.2.3-",
+		"1.2.3+",
+		"1.2.3-alpha..1",
+		"1.2.3-alpha.",
+		"1.2.3-01",
+		"1.2.3-alpha.01",
+		"1.2.3-alpha_beta",
+		"1.2.3+build+meta",
+		"1.2.3+build..1",
+		"1.2.3-é",
+	} {
+		if v, err := semver.Parse(s); !errors.Is(err, semver.ErrInvalidVersion) {
+			t.Errorf("Parse(%q) = %v, %v, want %v", s, v, err, semver.ErrInvalidVersion)
+		}
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		version string
+		kind    string
+		want    string
+	}{
+		{"1.2.3", semver.Major, "2.0.0"},
+		{"1.2.3", semver.Minor, "1.3.0"},
+		{"1.2.3", semver.Patch, "1.2.4"},
+		{"1.2.3+build.1", semver.Patch, "1.2.4"},
+		{"2.0.0-rc.1", semver.Major, "2.0.0"},
+		{"2.1.0-rc.1", semver.Major, "3.0.0"},
+		{"1.3.0-beta", semver.Minor, "1.3.0"},
+		{"1.3.1-beta", semver.Minor, "1.4.0"},
+		{"1.2.4-alpha", semver.Patch, "1.2.4"},
+		{"0.0.0", semver.Patch, "0.0.1"},
+	}
+	for _, tt := range tests {
+		got, err := mustParse(t, tt.version).Bump(tt.kind)
+		if err != nil {
+			t.Errorf("%s bump %s: %v", tt.version, tt.kind, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s bump %s = %s, want %s", tt.version, tt.kind, got, tt.want)
+		}
+	}
+	if _, err := mustParse(t, "1.2.3").Bump("build"); err == nil {
+		t.Error("unknown bump kind was accepted")
+	}
+}